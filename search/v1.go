@@ -1,19 +1,34 @@
 package search
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/tidwall/collate"
 )
 
 const v1IndexCapacity = 32
 
+// V1SortByScore in SortBys sorts by the relevance score
+const V1SortByScore = "_score"
+
+const (
+	V1OpTypeIndex  = "index"
+	V1OpTypeCreate = "create"
+)
+
+// v1TimeoutCheckInterval is how many docs are scanned between two checks of the query timeout
+const v1TimeoutCheckInterval = 256
+
 var (
 	v1Indices      []*v1IndexWrapper
 	v1IndexLock    *sync.RWMutex
@@ -24,7 +39,8 @@ func init() {
 	v1Indices = make([]*v1IndexWrapper, v1IndexCapacity)
 	for i := 0; i < v1IndexCapacity; i++ {
 		v1Indices[i] = &v1IndexWrapper{
-			Naive: make(map[string]*V1Doc),
+			Naive:  make(map[string]*V1Doc),
+			recent: newV1RecentIndex(nil),
 		}
 	}
 
@@ -34,11 +50,151 @@ func init() {
 }
 
 type v1IndexWrapper struct {
+	// lastAccess is the unix nano of the latest query or write, kept first for 64-bit atomic alignment
+	lastAccess int64
+
+	Name        string            `json:"name"`
 	Initialized bool              `json:"initialized"`
 	Lock        *sync.RWMutex     `json:"lock"`
 	Naive       map[string]*V1Doc `json:"naive"`
+	Config      V1IndexConfig     `json:"config"`
+
+	// docs holds the immutable []*V1Doc snapshot when copy-on-write is enabled
+	docs atomic.Value
+
+	// recent orders the docs by ModifiedAt
+	recent *v1RecentIndex
+
+	// seqNo is bumped on every write, it's stamped on the put doc and doubles as the index version
+	seqNo int64
+
+	// trash holds the soft-deleted docs by ID
+	trash map[string]*v1TrashedDoc
+
+	// bytes sums the SourceBytes of the docs, the soft-deleted ones included, see memoryBytes
+	bytes int64
+
+	// synonyms maps a term to its synonyms, it's replaced as a whole and never modified
+	synonyms map[string][]string
+
+	// readOnly marks a replica, only a refresh changes its docs, see V1AttachReplica
+	readOnly bool
+}
+
+// writable fails with ErrReadOnly if the index is a replica, the caller must hold the lock
+func (w *v1IndexWrapper) writable() error {
+	if w.readOnly {
+		return fmt.Errorf("%w: %s is a replica", ErrReadOnly, w.Name)
+	}
+
+	return nil
+}
+
+// set stores the doc, the caller must hold the write lock
+func (w *v1IndexWrapper) set(doc *V1Doc) {
+	v1Analyze(w.Config, doc)
+	if existing, found := w.Naive[doc.ID]; found {
+		w.bytes -= existing.SourceBytes
+	}
+	w.bytes += doc.SourceBytes
+	w.Naive[doc.ID] = doc
+	w.recent.set(doc)
+	w.publish()
+}
+
+// remove drops the doc, the caller must hold the write lock
+func (w *v1IndexWrapper) remove(id string) {
+	if existing, found := w.Naive[id]; found {
+		w.bytes -= existing.SourceBytes
+	}
+	delete(w.Naive, id)
+	w.recent.remove(id)
+	w.publish()
+}
+
+// oldest returns the least recently modified doc, off the back of the recent index
+func (w *v1IndexWrapper) oldest() *V1Doc {
+	return w.recent.oldest(nil)
+}
+
+// lookup returns the existing docs among the IDs, once each, the caller must hold the lock
+func (w *v1IndexWrapper) lookup(ids []string) []*V1Doc {
+	docs := make([]*V1Doc, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if doc, found := w.Naive[id]; found && !seen[id] {
+			seen[id] = true
+			docs = append(docs, doc)
+		}
+	}
+
+	return docs
+}
+
+// load replaces all docs, the caller must hold the write lock
+func (w *v1IndexWrapper) load(docs []*V1Doc) {
+	w.Naive = make(map[string]*V1Doc, len(docs))
+	w.bytes = 0
+	for _, trashed := range w.trash {
+		w.bytes += trashed.doc.SourceBytes
+	}
+	for _, doc := range docs {
+		v1Analyze(w.Config, doc)
+		if existing, found := w.Naive[doc.ID]; found {
+			w.bytes -= existing.SourceBytes
+		}
+		w.bytes += doc.SourceBytes
+		w.Naive[doc.ID] = doc
+		if doc.SeqNo > w.seqNo {
+			w.seqNo = doc.SeqNo
+		}
+	}
+	w.recent = newV1RecentIndex(w.Naive)
+	w.publish()
+}
+
+// copies returns shallow copies of all docs, the caller must hold the lock
+func (w *v1IndexWrapper) copies() []*V1Doc {
+	docs := make([]*V1Doc, 0, len(w.Naive))
+	for _, doc := range w.Naive {
+		clone := *doc
+		docs = append(docs, &clone)
+	}
+
+	return docs
+}
+
+// reset drops all docs, the caller must hold the write lock
+func (w *v1IndexWrapper) reset() {
+	w.Naive = make(map[string]*V1Doc)
+	w.trash = nil
+	w.bytes = 0
+	w.recent = newV1RecentIndex(w.Naive)
+	w.publish()
+}
+
+// publish swaps in a fresh snapshot if copy-on-write is enabled, the caller must hold the write lock
+func (w *v1IndexWrapper) publish() {
+	if !w.Config.CopyOnWrite {
+		return
+	}
+
+	docs := make([]*V1Doc, 0, len(w.Naive))
+	for _, doc := range w.Naive {
+		docs = append(docs, doc)
+	}
+
+	w.docs.Store(docs)
+}
+
+// snapshot returns the latest published docs, the slice must not be modified
+func (w *v1IndexWrapper) snapshot() []*V1Doc {
+	docs, _ := w.docs.Load().([]*V1Doc)
+	return docs
 }
 
+// V1Doc is a stored doc, it's never modified once stored, a write replaces it as a whole,
+// so readers holding it after the lock is released (or across a reset) stay safe
 type V1Doc struct {
 	ID         string                 `json:"_id"`
 	SortableID int64                  `json:"_sortable_id"`
@@ -47,6 +203,24 @@ type V1Doc struct {
 	Index      string                 `json:"_index"`
 	ModifiedAt int64                  `json:"_modified_at"`
 	CreatedAt  int64                  `json:"_created_at"`
+	// SeqNo is the per-index sequence number of the latest write of the doc
+	SeqNo int64 `json:"_seq_no"`
+	// RawKeywords keeps the original values of the keywords changed by normalization
+	RawKeywords map[string]string `json:"_raw_keywords,omitempty"`
+
+	// KeywordsNum holds the parsed values of the numeric fields of the index
+	KeywordsNum map[string]float64 `json:"_keywords_num,omitempty"`
+	// BoostValue multiplies the score of the doc at query time, 0 means unboosted
+	BoostValue float64 `json:"_boost_value,omitempty"`
+	// ContentHash is the hash of the keywords, source and boost of the doc, only kept if the index dedupes
+	ContentHash string `json:"_content_hash,omitempty"`
+
+	// Tokens are the analyzed keywords, rebuilt whenever the doc is stored
+	Tokens map[string][]string `json:"-"`
+	// KeywordBytes is the size of the keyword names and values, what a scan of the doc costs
+	KeywordBytes int `json:"-"`
+	// SourceBytes is the estimated size of the source, what the doc counts to the memory usage of the index
+	SourceBytes int64 `json:"-"`
 }
 
 // V1Request is the request of search v1
@@ -58,45 +232,268 @@ type V1Request struct {
 	ID       string                 `json:"id,omitempty"`
 	Keywords map[string]string      `json:"keywords,omitempty"`
 	Source   map[string]interface{} `json:"source,omitempty"`
+	Facets   *V1Facets              `json:"facets,omitempty"`
+	// PostFilter narrows the hits after the facets are tallied over the full match set
+	PostFilter *V1RequestQuery `json:"post_filter,omitempty"`
+	// GroupBy partitions the ranked hits by a field value
+	GroupBy *V1GroupBy `json:"group_by,omitempty"`
+	// Highlight marks where the regexes of the query hit in the returned docs
+	Highlight bool `json:"highlight,omitempty"`
+	// HighlightFields limits highlighting to the listed fields, all matched fields if empty
+	HighlightFields []string `json:"highlight_fields,omitempty"`
+	// HighlightOptions sets the tags and the encoding of the snippets, nil means "<em>" and "</em>" unencoded
+	HighlightOptions *V1HighlightOptions `json:"highlight_options,omitempty"`
+	// SourceEnabled false leaves the _source of the hits empty, for the callers rendering only the highlights.
+	// Nil means true
+	SourceEnabled *bool `json:"source_enabled,omitempty"`
+	// EchoQuery returns the query as it was executed in the response
+	EchoQuery bool `json:"echo_query,omitempty"`
+	// BoostValue is stored on the put doc, see V1Doc
+	BoostValue float64 `json:"boost_value,omitempty"`
+	// MaxScan stops the scan after that many docs in no particular order, bounding the latency
+	// at the cost of approximate results, 0 means unlimited
+	MaxScan int `json:"max_scan,omitempty"`
+	// PageToken is the NextPageToken of the previous page, From is ignored when it's set
+	PageToken string `json:"page_token,omitempty"`
+	// StatsAggs lists the numeric fields to summarize over the matched docs, ahead of the PostFilter
+	StatsAggs []string `json:"stats_aggs,omitempty"`
+	// ReturnMatchCounts annotates every hit with the clauses it matched
+	ReturnMatchCounts bool `json:"return_match_counts,omitempty"`
+	// TimeoutMs is the budget of the scan, 0 means unlimited, see V1Response.TimedOut
+	TimeoutMs int `json:"timeout_ms,omitempty"`
+	// ExternalScores are per-ID scores from another system, blended into the score of the matched docs
+	// by ScoreCombiner, "sum" (the default), "max", "multiply" or "weighted" by ExternalWeight.
+	// Docs without an external score keep theirs
+	ExternalScores map[string]float64 `json:"external_scores,omitempty"`
+	ScoreCombiner  string             `json:"score_combiner,omitempty"`
+	ExternalWeight float64            `json:"external_weight,omitempty"`
+	// OpType is how a put treats an existing doc, "index" (the default) overwrites it and "create" fails
+	OpType string `json:"op_type,omitempty"`
+	// Indices queries every listed index instead of Index, scanning up to Parallelism of them at once,
+	// 0 means GOMAXPROCS. MaxScan applies to each index
+	Indices     []string `json:"indices,omitempty"`
+	Parallelism int      `json:"parallelism,omitempty"`
+	// Decay multiplies the keyword score of a doc by a factor lowering as the doc ages, ahead of BoostValue
+	// and the ExternalScores
+	Decay *V1Decay `json:"decay,omitempty"`
 }
 
 // V1Response is the response of search v1
 type V1Response struct {
-	Took int64          `json:"took"`
-	Hits V1ResponseHits `json:"hits"`
+	Took   int64                       `json:"took"`
+	Hits   V1ResponseHits              `json:"hits"`
+	Facets map[string][]*V1FacetBucket `json:"facets,omitempty"`
+	// Stats are the StatsAggs by field
+	Stats map[string]*V1FieldStats `json:"stats,omitempty"`
+	// Groups are the ranked hits partitioned by the GroupBy field
+	Groups []*V1ResponseGroup `json:"groups,omitempty"`
+	// SeqNo is the latest sequence number of the index, pass it as SinceSeqNo to fetch the following changes,
+	// 0 over several Indices
+	SeqNo int64 `json:"seq_no"`
+	// Warnings tells about the docs skipped because of errors, the rest of the results are still valid
+	Warnings []string `json:"warnings,omitempty"`
+	// Query is the executed query if EchoQuery is set
+	Query *V1EchoedQuery `json:"query,omitempty"`
+	// Approximate tells the scan stopped at MaxScan docs, so matches among the rest are missing
+	Approximate bool `json:"approximate,omitempty"`
+	// NextPageToken fetches the page after this one as PageToken, empty on the last page
+	NextPageToken string `json:"next_page_token,omitempty"`
+	// TimedOut tells the scan stopped at TimeoutMs or when the request was canceled,
+	// the hits are the ones collected until then, still sorted and paged
+	TimedOut bool `json:"timed_out"`
+	// DocsScanned and BytesScanned are the work done by the query, for metering,
+	// the bytes are the keywords of the scanned docs
+	DocsScanned  int `json:"docs_scanned"`
+	BytesScanned int `json:"bytes_scanned"`
+	// Failures are the Indices the query failed on, the hits are from the rest
+	Failures []*V1IndexFailure `json:"failures,omitempty"`
 }
 
 type V1RequestQuery struct {
-	RawAnds  []string                  `json:"raw,omitempty"`
-	RawOrs   []string                  `json:"raw_ors,omitempty"`
-	RegsAnd  map[string]*regexp.Regexp `json:"regs_and,omitempty"`
-	RegsOr   map[string]*regexp.Regexp `json:"regs_or,omitempty"`
-	Filters  map[string]string         `json:"filters,omitempty"`
-	SortMode string                    `json:"sort_mode,omitempty"`
-	SortBys  string                    `json:"sort_bys,omitempty"`
+	RawAnds []string                  `json:"raw,omitempty"`
+	RawOrs  []string                  `json:"raw_ors,omitempty"`
+	RegsAnd map[string]*regexp.Regexp `json:"regs_and,omitempty"`
+	RegsOr  map[string]*regexp.Regexp `json:"regs_or,omitempty"`
+	// AnyField matches if the regex matches any keyword value of the doc, whatever the field
+	AnyField *regexp.Regexp `json:"any_field,omitempty"`
+	// MultiMatch matches if its pattern matches any of the listed fields
+	MultiMatch *V1MultiMatch     `json:"multi_match,omitempty"`
+	Filters    map[string]string `json:"filters,omitempty"`
+	// SinceSeqNo only matches the docs written after the given sequence number and orders them by it,
+	// 0 means disabled
+	SinceSeqNo int64 `json:"since_seq_no,omitempty"`
+	// Ranges matches if every listed numeric field is within its range
+	Ranges map[string]*V1Range `json:"ranges,omitempty"`
+	// TermsAll matches if the tokenized field contains every listed term
+	TermsAll map[string][]string `json:"terms_all,omitempty"`
+	// Terms matches if the tokenized field contains the listed terms combined by DefaultOperator,
+	// every listed field has to match
+	Terms map[string][]string `json:"terms,omitempty"`
+	// DefaultOperator combines the Terms of a field, "and" needs all of them, "or" (the default) any of them
+	DefaultOperator string `json:"default_operator,omitempty"`
+	// ScriptSort is an arithmetic expression over numeric keyword fields, e.g. "likes - dislikes",
+	// its value is the primary sort key, ahead of SortBys
+	ScriptSort string `json:"script_sort,omitempty"`
+	SortMode   string `json:"sort_mode,omitempty"`
+	SortBys    string `json:"sort_bys,omitempty"`
+	// Collation overrides the index's collation of the SortBys keywords
+	Collation string `json:"collation,omitempty"`
+	// IDs restricts the candidates to the listed docs, which are looked up instead of scanned for
+	IDs []string `json:"ids,omitempty"`
+	// GeoDistance matches the docs within a distance of a point
+	GeoDistance *V1GeoDistance `json:"geo_distance,omitempty"`
+	// GeoSort orders by the distance from a point, ahead of every other sort key
+	GeoSort *V1GeoSort `json:"geo_sort,omitempty"`
+
+	// synonyms are the synonyms of the index the terms were expanded with
+	synonyms map[string][]string
+}
+
+// V1Range bounds a numeric field, nil bounds are open
+type V1Range struct {
+	Gt  *float64 `json:"gt,omitempty"`
+	Gte *float64 `json:"gte,omitempty"`
+	Lt  *float64 `json:"lt,omitempty"`
+	Lte *float64 `json:"lte,omitempty"`
+}
+
+func (r *V1Range) contains(v float64) bool {
+	return (r.Gt == nil || v > *r.Gt) &&
+		(r.Gte == nil || v >= *r.Gte) &&
+		(r.Lt == nil || v < *r.Lt) &&
+		(r.Lte == nil || v <= *r.Lte)
+}
+
+func (r *V1Range) String() string {
+	if r == nil {
+		return ""
+	}
+
+	bounds := make([]string, 0, 4)
+	for _, bound := range []struct {
+		op    string
+		value *float64
+	}{{">", r.Gt}, {">=", r.Gte}, {"<", r.Lt}, {"<=", r.Lte}} {
+		if bound.value != nil {
+			bounds = append(bounds, bound.op+strconv.FormatFloat(*bound.value, 'g', -1, 64))
+		}
+	}
+
+	return strings.Join(bounds, " ")
+}
+
+// v1ParseFilterRange parses the range syntax of a filter value, "[10 TO 100]" with inclusive bounds,
+// "{10 TO 100}" with exclusive ones, "*" for an open bound, or a single comparison like ">5" or "<=3",
+// ok is false if the value isn't range syntax
+func v1ParseFilterRange(value string) (r *V1Range, ok bool) {
+	value = strings.TrimSpace(value)
+
+	for _, op := range []string{">=", "<=", ">", "<"} {
+		if !strings.HasPrefix(value, op) {
+			continue
+		}
+
+		n, err := strconv.ParseFloat(strings.TrimSpace(value[len(op):]), 64)
+		if err != nil {
+			return nil, false
+		}
+
+		switch op {
+		case ">=":
+			return &V1Range{Gte: &n}, true
+		case "<=":
+			return &V1Range{Lte: &n}, true
+		case ">":
+			return &V1Range{Gt: &n}, true
+		default:
+			return &V1Range{Lt: &n}, true
+		}
+	}
+
+	if len(value) < 2 {
+		return nil, false
+	}
+
+	left, right := value[0], value[len(value)-1]
+	if (left != '[' && left != '{') || (right != ']' && right != '}') {
+		return nil, false
+	}
+
+	bounds := strings.Fields(value[1 : len(value)-1])
+	if len(bounds) != 3 || !strings.EqualFold(bounds[1], "TO") {
+		return nil, false
+	}
+
+	r = &V1Range{}
+	if bounds[0] != "*" {
+		n, err := strconv.ParseFloat(bounds[0], 64)
+		if err != nil {
+			return nil, false
+		}
+		if left == '[' {
+			r.Gte = &n
+		} else {
+			r.Gt = &n
+		}
+	}
+	if bounds[2] != "*" {
+		n, err := strconv.ParseFloat(bounds[2], 64)
+		if err != nil {
+			return nil, false
+		}
+		if right == ']' {
+			r.Lte = &n
+		} else {
+			r.Lt = &n
+		}
+	}
+
+	return r, true
+}
+
+// V1MultiMatch matches a single pattern against several fields
+type V1MultiMatch struct {
+	Pattern *regexp.Regexp `json:"pattern"`
+	Fields  []string       `json:"fields"`
+}
+
+// boost returns the factor the score of the doc is multiplied by
+func (d *V1Doc) boost() float64 {
+	if d.BoostValue == 0 {
+		return 1
+	}
+
+	return d.BoostValue
 }
 
 // Hits is the hits of search v1
 type V1ResponseHits struct {
-	From     int      `json:"from"`
-	Size     int      `json:"size"`
-	Total    int      `json:"total"`
-	MaxScore int64    `json:"max_score"`
-	Hits     []*V1Doc `json:"hits"`
+	From     int              `json:"from"`
+	Size     int              `json:"size"`
+	Total    int              `json:"total"`
+	MaxScore float64          `json:"max_score"`
+	Hits     []*V1ResponseHit `json:"hits"`
 }
 
 // V1ResponseHit is the hit of search v1
 type V1ResponseHit struct {
 	ID         string                 `json:"_id"`
 	Source     map[string]interface{} `json:"_source"`
-	Score      int64                  `json:"_score"`
+	Score      float64                `json:"_score"`
 	Index      string                 `json:"_index"`
-	Highlights []*V1ResponseHighlight `json:"_highlights"`
+	Highlights []*V1ResponseHighlight `json:"_highlights,omitempty"`
+	// MatchCounts are the clauses the doc matched, if ReturnMatchCounts is set
+	MatchCounts *V1MatchCounts `json:"_match_counts,omitempty"`
+	// Distance is the km from the point of the GeoSort, or else of the GeoDistance
+	Distance *float64 `json:"_distance,omitempty"`
 }
 
 type V1ResponseHighlight struct {
-	Field   string   `json:"field"`
+	Field string `json:"field"`
+	// Offsets are the "start-end" byte ranges of the matches in the field value
 	Offsets []string `json:"offsets"`
+	// Snippet is the field value with the matches wrapped in tags
+	Snippet string `json:"snippet"`
 }
 
 func V1Index(c *gin.Context, index string) error {
@@ -105,121 +502,290 @@ func V1Index(c *gin.Context, index string) error {
 		return nil
 	}
 
+	// Created in between is as good
+	if err := v1CreateIndex(c, index, nil); err != nil && !errors.Is(err, ErrIndexExists) {
+		return err
+	}
+
+	return nil
+}
+
+// v1CreateIndex takes a slot for the index, filled by load under its write lock if set or else configured
+// by the templates. The index is only visible once it's loaded, it fails with ErrIndexExists if it's there already
+func v1CreateIndex(c *gin.Context, index string, load func(w *v1IndexWrapper)) error {
 	v1IndexLock.Lock()
-	defer v1IndexLock.Unlock()
 
 	// check if index exists again
+	if _, found := v1IndexMapping[index]; found {
+		v1IndexLock.Unlock()
+		return fmt.Errorf("%w: %s", ErrIndexExists, index)
+	}
+
+	// Writes to an alias would otherwise land in an index queries on the alias never see
+	if v1IsAlias(index) {
+		v1IndexLock.Unlock()
+		return fmt.Errorf("index %s collides with an alias", index)
+	}
+
+	offset := -1
 	for i := 0; i < v1IndexCapacity; i++ {
 		if !v1Indices[i].Initialized {
-			v1Indices[i].Initialized = true
-			v1Indices[i].Lock = &sync.RWMutex{}
-			v1IndexMapping[index] = i
-			return nil
+			offset = i
+			break
 		}
 	}
 
-	return fmt.Errorf("index capacity exceeded")
+	evicted := ""
+	var evictions *v1Evictions
+	if offset < 0 && v1OverflowPolicy == V1OverflowPolicyEvictLRU {
+		offset, evicted, evictions = v1EvictColdest()
+	}
+
+	if offset < 0 {
+		v1IndexLock.Unlock()
+		return fmt.Errorf("%w: no free index slot for %s", ErrCapacityExceeded, index)
+	}
+
+	if v1Indices[offset].Lock == nil {
+		v1Indices[offset].Lock = &sync.RWMutex{}
+	}
+	// Named under the slot's lock too, v1LockIndex checks the name holding only that one
+	v1Indices[offset].Lock.Lock()
+	v1Indices[offset].Initialized = true
+	v1Indices[offset].Name = index
+	if load != nil {
+		load(v1Indices[offset])
+	} else if config, found := v1MatchTemplate(index); found {
+		v1Indices[offset].Config = config
+		v1Indices[offset].reset()
+	}
+	v1Indices[offset].Lock.Unlock()
+	v1Indices[offset].touch()
+	v1IndexMapping[index] = offset
+
+	v1IndexLock.Unlock()
+
+	evictions.notify()
+
+	if evicted != "" {
+		V1DisableAutoSnapshot(c, evicted)
+		v1Emit(&V1ChangeEvent{Type: V1EventIndexEvicted, Index: evicted})
+	}
+
+	return nil
 }
 
 func V1GetIndexMapping(index string) int {
 	v1IndexLock.RLock()
-	defer v1IndexLock.RUnlock()
+	offset, found := v1IndexMapping[index]
+	v1IndexLock.RUnlock()
 
-	if offset, found := v1IndexMapping[index]; found {
+	if found {
 		return offset
 	}
 
+	// A spilled index is reloaded on first use
+	if v1Reload(index) {
+		return V1GetIndexMapping(index)
+	}
+
 	return -1
 }
 
-func V1(ctx *gin.Context, request *V1Request) *V1Response {
-	offset := V1GetIndexMapping(request.Index)
-	if offset < 0 {
-		return &V1Response{}
-	}
+// v1LockIndex looks the index up and locks its slot, for writing if write is set, and returns it along with
+// the unlock. An LRU eviction may hand the slot to another index between the lookup and the lock, so the name
+// is checked under the lock and the lookup retried, it fails with ErrIndexNotFound once the index is gone
+func v1LockIndex(index string, write bool) (*v1IndexWrapper, func(), error) {
+	for {
+		offset := V1GetIndexMapping(index)
+		if offset < 0 {
+			return nil, nil, fmt.Errorf("%w: %s", ErrIndexNotFound, index)
+		}
 
-	v1Indices[offset].Lock.RLock()
-	defer v1Indices[offset].Lock.RUnlock()
+		w := v1Indices[offset]
+		lock, unlock := w.Lock.RLock, w.Lock.RUnlock
+		if write {
+			lock, unlock = w.Lock.Lock, w.Lock.Unlock
+		}
 
-	recalls := make([]*V1Doc, 0)
+		lock()
+		if w.Name == index {
+			return w, unlock, nil
+		}
+		unlock()
+	}
+}
 
-	for _, doc := range v1Indices[offset].Naive {
-		matchedAndCount := 0
-		matchedOrCount := 0
+// v1CreateAndLockIndex creates the index if needed and write locks it, it's created again if it's evicted
+// before it's locked
+func v1CreateAndLockIndex(ctx *gin.Context, index string) (*v1IndexWrapper, func(), error) {
+	for {
+		if err := V1Index(ctx, index); err != nil {
+			return nil, nil, err
+		}
 
-		matchedAnd := true
-		matchedOr := true
+		if w, unlock, err := v1LockIndex(index, true); err == nil {
+			return w, unlock, nil
+		}
+	}
+}
 
-		matchedFilter := true
-		if len(request.Query.Filters) > 0 {
-			matchedFilter = false
+// V1 runs the query against the index, or against every index of Indices concurrently, their hits merged
+// and ranked together. It fails with ErrIndexNotFound or if the request is invalid, over Indices the failing
+// ones are reported in Failures instead and it only fails if every index does
+func V1(ctx *gin.Context, request *V1Request) (*V1Response, error) {
+	var script *v1Script
+	if request.Query.ScriptSort != "" {
+		var err error
+		if script, err = v1ParseScript(request.Query.ScriptSort); err != nil {
+			return nil, err
 		}
+	}
 
-		for k, v := range doc.Keywords {
-			if reg := request.Query.RegsAnd[k]; reg != nil {
-				if reg.MatchString(v) {
-					matchedAndCount++
-				}
-			}
+	if err := v1ValidateCombiner(request.ScoreCombiner, request.ExternalWeight); err != nil {
+		return nil, err
+	}
 
-			if reg := request.Query.RegsOr[k]; reg != nil {
-				if reg.MatchString(v) {
-					matchedOrCount++
-				}
-			}
+	if err := request.HighlightOptions.validate(); err != nil {
+		return nil, err
+	}
 
-			if filter := request.Query.Filters[k]; len(filter) > 0 {
-				filterBuckets := make(map[string]bool, 0)
-				for _, f := range strings.Split(filter, ",") {
-					filterBuckets[f] = true
-				}
+	if err := request.Decay.validate(); err != nil {
+		return nil, err
+	}
 
-				if _, exists := filterBuckets[v]; exists {
-					matchedFilter = true
-				}
-			}
+	for _, query := range []*V1RequestQuery{request.Query, request.PostFilter} {
+		if err := v1ValidateOperator(query); err != nil {
+			return nil, err
 		}
+	}
 
-		if len(request.Query.RegsAnd) > 0 {
-			matchedAnd = matchedAndCount == len(request.Query.RegsAnd)
+	var after *v1Recall
+	if request.PageToken != "" {
+		var err error
+		if after, err = v1DecodePageToken(v1QueryHash(request), request.PageToken); err != nil {
+			return nil, err
 		}
+	}
+
+	// The scans stop once the budget elapses or the client goes away
+	var done <-chan struct{}
+	if ctx != nil && ctx.Request != nil {
+		done = ctx.Request.Context().Done()
+	}
+	var deadline time.Time
+	if request.TimeoutMs > 0 {
+		deadline = time.Now().Add(time.Duration(request.TimeoutMs) * time.Millisecond)
+	}
 
-		if len(request.Query.RegsOr) > 0 {
-			matchedOr = matchedOrCount > 0
+	index := v1ResolveAlias(request.Index)
+	indices := []string{request.Index}
+	if len(request.Indices) > 0 {
+		index = strings.Join(request.Indices, ",")
+		indices = v1UniqueIndices(request.Indices)
+	}
+
+	scans := v1ScanIndices(request, indices, time.Now(), done, deadline)
+
+	// The hits of every index are ranked by the query as the first index normalized it
+	recalls := make([]*v1Recall, 0)
+	excluded := make(map[string]map[string]int)
+	var query *V1RequestQuery
+	var failures []*V1IndexFailure
+	var failed error
+	scanned, scannedBytes, approximate, timedOut := 0, 0, false, false
+	for i, scan := range scans {
+		if scan.err != nil {
+			if failed == nil {
+				failed = scan.err
+			}
+			failures = append(failures, &V1IndexFailure{Index: indices[i], Error: scan.err.Error()})
+			continue
 		}
 
-		if matchedAnd && matchedOr && matchedFilter {
-			recalls = append(recalls, doc)
+		if query == nil {
+			query = scan.query
+		}
+		recalls = append(recalls, scan.recalls...)
+		for field, counts := range scan.facets {
+			if excluded[field] == nil {
+				excluded[field] = make(map[string]int, len(counts))
+			}
+			for v, c := range counts {
+				excluded[field][v] += c
+			}
 		}
+		scanned += scan.scanned
+		scannedBytes += scan.scannedBytes
+		approximate = approximate || scan.approximate
+		timedOut = timedOut || scan.timedOut
+	}
+
+	if query == nil {
+		return nil, failed
+	}
+
+	geo := v1QueryGeo(request.Query)
+
+	var seqNo int64
+	if len(scans) == 1 {
+		seqNo = scans[0].seqNo
 	}
 
-	sort.SliceStable(recalls, func(i, j int) bool {
-		for _, sortBy := range strings.Split(request.Query.SortBys, ",") {
-			vi := recalls[i].Keywords[sortBy]
-			vj := recalls[j].Keywords[sortBy]
+	warnings := make([]string, 0)
 
-			if vi == vj {
+	if script != nil {
+		// A doc the script fails on is skipped rather than failing the whole query
+		evaluated := make([]*v1Recall, 0, len(recalls))
+		for _, recall := range recalls {
+			var err error
+			if recall.Script, err = script.eval(recall.Doc); err != nil {
+				warnings = append(warnings, err.Error())
 				continue
 			}
+			evaluated = append(evaluated, recall)
+		}
+		recalls = evaluated
+	}
 
-			if request.Query.SortMode == "asc" {
-				return vi < vj
-			}
+	v1SortRecalls(query, recalls)
 
-			return vi > vj
+	var facets map[string][]*V1FacetBucket
+	if request.Facets != nil && len(request.Facets.Fields) > 0 {
+		tally := v1TallyFacets(recalls, request.Facets)
+		for field, counts := range excluded {
+			tally[field] = counts
 		}
+		facets = v1FacetBuckets(tally, request.Facets)
+	}
 
-		if request.Query.SortMode == "asc" {
-			return recalls[i].SortableID < recalls[j].SortableID
-		}
+	var stats map[string]*V1FieldStats
+	if len(request.StatsAggs) > 0 {
+		stats = v1Stats(recalls, request.StatsAggs)
+	}
 
-		return recalls[i].SortableID > recalls[j].SortableID
-	})
+	if request.PostFilter != nil {
+		filtered := make([]*v1Recall, 0, len(recalls))
+		for _, recall := range recalls {
+			if !recall.filtered {
+				filtered = append(filtered, recall)
+			}
+		}
+		recalls = filtered
+	}
 
 	if request.From < 0 || request.From > int64(len(recalls)) {
 		request.From = 0
 	}
 
+	if after != nil {
+		// Resume right after the last hit of the previous page
+		less := v1RecallLess(query)
+		request.From = int64(sort.Search(len(recalls), func(i int) bool {
+			return less(after, recalls[i])
+		}))
+	}
+
 	if request.Size <= 0 || request.Size > 200 {
 		request.Size = 10
 	}
@@ -230,30 +796,450 @@ func V1(ctx *gin.Context, request *V1Request) *V1Response {
 			Size:  int(request.Size),
 			Total: len(recalls),
 		},
+		Facets:       facets,
+		Stats:        stats,
+		SeqNo:        seqNo,
+		Warnings:     v1TruncateWarnings(warnings),
+		Approximate:  approximate,
+		TimedOut:     timedOut,
+		DocsScanned:  scanned,
+		BytesScanned: scannedBytes,
+		Failures:     failures,
+	}
+
+	if request.EchoQuery {
+		response.Query = v1EchoQuery(index, query)
+	}
+
+	for _, recall := range recalls {
+		if recall.Score > response.Hits.MaxScore {
+			response.Hits.MaxScore = recall.Score
+		}
+	}
+
+	var highlighter *v1Highlighter
+	if request.Highlight {
+		highlighter = newV1Highlighter(query, request.HighlightFields, request.HighlightOptions)
+	}
+
+	withSource := request.SourceEnabled == nil || *request.SourceEnabled
+
+	hit := func(recall *v1Recall) *V1ResponseHit {
+		hit := recall.hit(withSource)
+		if highlighter != nil {
+			hit.Highlights = highlighter.highlight(recall.Doc)
+		}
+		if request.ReturnMatchCounts {
+			hit.MatchCounts = recall.Match.counts()
+		}
+		if geo != nil && !math.IsInf(recall.Distance, 1) {
+			distance := recall.Distance
+			hit.Distance = &distance
+		}
+		return hit
 	}
 
 	if response.Hits.Total > 0 {
-		if request.From+request.Size > int64(len(recalls)) {
-			response.Hits.Hits = recalls[request.From:]
-		} else {
-			response.Hits.Hits = recalls[request.From : request.From+request.Size]
+		page := recalls[request.From:]
+		if request.From+request.Size <= int64(len(recalls)) {
+			page = recalls[request.From : request.From+request.Size]
+		}
+
+		response.Hits.Hits = make([]*V1ResponseHit, 0, len(page))
+		for _, recall := range page {
+			response.Hits.Hits = append(response.Hits.Hits, hit(recall))
+		}
+
+		if len(page) > 0 && request.From+int64(len(page)) < int64(len(recalls)) {
+			response.NextPageToken = v1EncodePageToken(v1QueryHash(request), query, page[len(page)-1])
+		}
+	}
+
+	if request.GroupBy != nil && request.GroupBy.Field != "" {
+		response.Groups = v1Group(recalls, request.GroupBy, hit)
+	}
+
+	return response, nil
+}
+
+// v1Scan is what a query collected from an index
+type v1Scan struct {
+	recalls []*v1Recall
+	// query is the query as the index normalized it
+	query *V1RequestQuery
+	seqNo int64
+	// facets are the tallies of the facet fields excluding their own filters, see V1Facets.ExcludeOwnFilters
+	facets map[string]map[string]int
+
+	scanned, scannedBytes int
+	approximate, timedOut bool
+
+	err error
+}
+
+// v1ScanIndex collects the docs of the index matching the query of the request, they decay by their age at now
+func v1ScanIndex(request *V1Request, name string, now time.Time, done <-chan struct{}, deadline time.Time) *v1Scan {
+	index := v1ResolveAlias(name)
+
+	expired := func() bool {
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			return true
+		}
+
+		select {
+		case <-done:
+			return true
+		default:
+			return false
+		}
+	}
+
+	geo := v1QueryGeo(request.Query)
+
+	scan := &v1Scan{recalls: make([]*v1Recall, 0)}
+
+	// collect reports false once MaxScan docs were scanned or the time is up, the rest of the docs are skipped
+	var postFilter *V1RequestQuery
+	var facetQueries map[string]*V1RequestQuery
+	collect := func(query *V1RequestQuery, doc *V1Doc) bool {
+		if request.MaxScan > 0 && scan.scanned >= request.MaxScan {
+			scan.approximate = true
+			return false
+		}
+
+		if (done != nil || !deadline.IsZero()) && scan.scanned%v1TimeoutCheckInterval == 0 && expired() {
+			scan.timedOut = true
+			return false
+		}
+		scan.scanned++
+		scan.scannedBytes += doc.KeywordBytes
+
+		for field, facetQuery := range facetQueries {
+			if v, found := doc.Keywords[field]; found && v1Match(facetQuery, doc, nil).Matched {
+				scan.facets[field][v]++
+			}
+		}
+
+		if result := v1Match(query, doc, nil); result.Matched {
+			score := float64(result.score()) * request.Decay.factor(doc, now) * doc.boost()
+			if external, found := request.ExternalScores[doc.ID]; found {
+				score = v1CombineScore(request.ScoreCombiner, request.ExternalWeight, score, external)
+			}
+			recall := &v1Recall{Doc: doc, Score: score, Match: result}
+			if geo != nil {
+				recall.Distance = v1GeoDistanceOf(doc, geo.Field, geo.Point)
+			}
+			if postFilter != nil {
+				recall.filtered = !v1Match(postFilter, doc, nil).Matched
+			}
+			scan.recalls = append(scan.recalls, recall)
+		}
+
+		return true
+	}
+
+	w, unlock, err := v1LockIndex(index, false)
+	if err != nil {
+		return &v1Scan{err: fmt.Errorf("%w: %s", ErrIndexNotFound, name)}
+	}
+	w.touch()
+	scan.seqNo = w.seqNo
+	synonyms := w.synonyms
+	query := v1NormalizeQuery(w.Config, v1ExpandSynonyms(synonyms, request.Query))
+	scan.query = query
+	if facetQueries = v1FacetQueries(query, request.Facets); len(facetQueries) > 0 {
+		scan.facets = make(map[string]map[string]int, len(facetQueries))
+		for field := range facetQueries {
+			scan.facets[field] = make(map[string]int)
+		}
+	}
+	if request.PostFilter != nil {
+		postFilter = v1NormalizeQuery(w.Config, v1ExpandSynonyms(synonyms, request.PostFilter))
+	}
+	if len(query.IDs) > 0 {
+		docs := w.lookup(query.IDs)
+		unlock()
+
+		for _, doc := range docs {
+			if !collect(query, doc) {
+				break
+			}
+		}
+	} else if w.Config.CopyOnWrite {
+		// Scan the immutable snapshot without blocking writers
+		docs := w.snapshot()
+		unlock()
+
+		for _, doc := range docs {
+			if !collect(query, doc) {
+				break
+			}
+		}
+	} else {
+		for _, doc := range w.Naive {
+			if !collect(query, doc) {
+				break
+			}
+		}
+		unlock()
+	}
+
+	return scan
+}
+
+// v1MaxWarnings caps the warnings of a response, the rest are only counted
+const v1MaxWarnings = 20
+
+func v1TruncateWarnings(warnings []string) []string {
+	if len(warnings) == 0 {
+		return nil
+	}
+
+	if len(warnings) > v1MaxWarnings {
+		more := len(warnings) - v1MaxWarnings
+		warnings = append(warnings[:v1MaxWarnings], fmt.Sprintf("%d more docs skipped", more))
+	}
+
+	return warnings
+}
+
+// v1Recall is a matched doc along with what was computed for it during the query
+type v1Recall struct {
+	Doc   *V1Doc
+	Score float64
+	Match v1MatchResult
+
+	// Distance is the km from the geo point of the query, +Inf if the doc has no location
+	Distance float64
+
+	// Script is the value of the ScriptSort expression
+	Script float64
+
+	// filtered tells the doc doesn't match the PostFilter
+	filtered bool
+}
+
+// hit returns the response of the recall, the source is copied so the caller can't touch the stored doc, or left empty without withSource
+func (r *v1Recall) hit(withSource bool) *V1ResponseHit {
+	var source map[string]interface{}
+	if !withSource {
+		source = make(map[string]interface{})
+	} else if r.Doc.Source != nil {
+		source = v1CopyValue(r.Doc.Source).(map[string]interface{})
+	}
+
+	return &V1ResponseHit{
+		ID:     r.Doc.ID,
+		Source: source,
+		Score:  r.Score,
+		Index:  r.Doc.Index,
+	}
+}
+
+// v1SortRecalls sorts by the GeoSort distance, then by the ScriptSort value, then by the SortBys keywords in order,
+// "_score" sorts by the score, ties are broken by SortableID then ID. Changes since a sequence number
+// are always in the order they were written
+func v1SortRecalls(query *V1RequestQuery, recalls []*v1Recall) {
+	less := v1RecallLess(query)
+	sort.Slice(recalls, func(i, j int) bool {
+		return less(recalls[i], recalls[j])
+	})
+}
+
+// v1RecallLess returns the total order v1SortRecalls sorts by
+func v1RecallLess(query *V1RequestQuery) func(a, b *v1Recall) bool {
+	sortBys := strings.Split(query.SortBys, ",")
+
+	collated := func(a, b string) bool { return a < b }
+	if query.Collation != "" {
+		collated = collate.IndexString(query.Collation)
+	}
+
+	if query.SinceSeqNo > 0 {
+		return func(a, b *v1Recall) bool {
+			return a.Doc.SeqNo < b.Doc.SeqNo
 		}
 	}
 
-	return response
+	asc := query.SortMode == "asc"
+
+	return func(a, b *v1Recall) bool {
+		if query.GeoSort != nil && a.Distance != b.Distance {
+			// Docs without a location come last either way
+			if math.IsInf(a.Distance, 1) || math.IsInf(b.Distance, 1) {
+				return math.IsInf(b.Distance, 1)
+			}
+
+			if query.GeoSort.Order == "desc" {
+				return a.Distance > b.Distance
+			}
+
+			return a.Distance < b.Distance
+		}
+
+		if query.ScriptSort != "" {
+			if a.Script != b.Script {
+				if asc {
+					return a.Script < b.Script
+				}
+
+				return a.Script > b.Script
+			}
+		}
+
+		for _, sortBy := range sortBys {
+			if sortBy == V1SortByScore {
+				if a.Score == b.Score {
+					continue
+				}
+
+				if asc {
+					return a.Score < b.Score
+				}
+
+				return a.Score > b.Score
+			}
+
+			// NumericFields sort by the pre-parsed values, the docs without one come after them either way
+			na, aNumeric := a.Doc.KeywordsNum[sortBy]
+			nb, bNumeric := b.Doc.KeywordsNum[sortBy]
+			if aNumeric != bNumeric {
+				return aNumeric
+			}
+
+			if aNumeric {
+				if na == nb {
+					continue
+				}
+
+				if asc {
+					return na < nb
+				}
+
+				return na > nb
+			}
+
+			// Values a collation considers equal fall through like equal ones
+			va := a.Doc.Keywords[sortBy]
+			vb := b.Doc.Keywords[sortBy]
+
+			if collated(va, vb) {
+				return asc
+			}
+
+			if collated(vb, va) {
+				return !asc
+			}
+		}
+
+		if a.Doc.SortableID != b.Doc.SortableID {
+			if asc {
+				return a.Doc.SortableID < b.Doc.SortableID
+			}
+
+			return a.Doc.SortableID > b.Doc.SortableID
+		}
+
+		// The same id may come from several indices
+		if a.Doc.ID != b.Doc.ID {
+			if asc {
+				return a.Doc.ID < b.Doc.ID
+			}
+
+			return a.Doc.ID > b.Doc.ID
+		}
+
+		if asc {
+			return a.Doc.Index < b.Doc.Index
+		}
+
+		return a.Doc.Index > b.Doc.Index
+	}
 }
 
+// V1Put stores the doc, it returns ErrNotModified if the index dedupes and the doc is unchanged
 func V1Put(ctx *gin.Context, request *V1Request) error {
-	offset := V1GetIndexMapping(request.Index)
-	if offset < 0 {
-		V1Index(ctx, request.Index)
-		offset = V1GetIndexMapping(request.Index)
+	result, err := v1Put(ctx, request)
+	if err == nil && result == V1ResultNoop {
+		return fmt.Errorf("%w: doc %s in index %s", ErrNotModified, request.ID, request.Index)
 	}
 
-	v1Indices[offset].Lock.Lock()
-	defer v1Indices[offset].Lock.Unlock()
+	return err
+}
+
+// v1Put stores the doc and reports whether it was created, updated or left as it was
+func v1Put(ctx *gin.Context, request *V1Request) (string, error) {
+	if err := v1ValidatePut(request); err != nil {
+		return "", err
+	}
+
+	// Deferred ahead of the unlock, so the event is emitted after the lock is released
+	var event *V1ChangeEvent
+	var evictions *v1Evictions
+	defer func() {
+		evictions.notify()
+		v1Emit(event)
+	}()
+
+	w, unlock, err := v1CreateAndLockIndex(ctx, request.Index)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	if err := w.writable(); err != nil {
+		return "", err
+	}
+
+	w.touch()
+
+	doc, result, err := w.prepare(request, w.Naive[request.ID])
+	if err != nil || doc == nil {
+		return result, err
+	}
+
+	if result == V1ResultCreated && w.full() {
+		if w.Config.MaxDocsPolicy != V1MaxDocsPolicyEvictOldest {
+			return "", fmt.Errorf("%w: index %s is full with %d docs", ErrCapacityExceeded, request.Index, w.Config.MaxDocs)
+		}
+
+		oldest := w.oldest()
+		w.remove(oldest.ID)
+		evictions = w.evictions(oldest)
+	}
+
+	w.seqNo++
+	doc.SeqNo = w.seqNo
+	w.set(doc)
+
+	event = &V1ChangeEvent{Type: V1EventPut, Index: request.Index, ID: request.ID}
+
+	return result, nil
+}
+
+// v1ValidatePut rejects the puts that are invalid whatever the index
+func v1ValidatePut(request *V1Request) error {
+	if request.BoostValue < 0 || math.IsNaN(request.BoostValue) {
+		return fmt.Errorf("invalid boost value %g", request.BoostValue)
+	}
+
+	switch request.OpType {
+	case "", V1OpTypeIndex, V1OpTypeCreate:
+	default:
+		return fmt.Errorf("unknown op type %s", request.OpType)
+	}
+
+	return nil
+}
+
+// prepare builds the doc the put stores over existing, nil if there's none, without changing the index.
+// The doc is nil with V1ResultNoop if the index dedupes and the content is unchanged. The caller must hold the lock
+func (w *v1IndexWrapper) prepare(request *V1Request, existing *V1Doc) (*V1Doc, string, error) {
+	if request.ID == "" {
+		request.ID = strconv.FormatInt(time.Now().UnixNano(), 10)
+	}
 
-	// Merge keywords into source
+	// Merge keywords into source as they were put, only the matched keywords are normalized
 	if request.Source == nil {
 		request.Source = make(map[string]interface{})
 	}
@@ -262,51 +1248,160 @@ func V1Put(ctx *gin.Context, request *V1Request) error {
 		request.Source[k] = v
 	}
 
+	// Keep the original values around for highlighting
+	normalized := v1NormalizeKeywords(w.Config, request.Keywords)
+	rawKeywords := v1ChangedKeywords(request.Keywords, normalized)
+	request.Keywords = normalized
+
+	if maxDocBytes := w.Config.MaxDocBytes; maxDocBytes > 0 {
+		docBytes, err := v1EstimateDocBytes(request.Source)
+		if err != nil {
+			return nil, "", fmt.Errorf("estimate doc %s size: %w", request.ID, err)
+		}
+
+		if docBytes > maxDocBytes {
+			return nil, "", fmt.Errorf("doc %s is %d bytes, exceeds index %s limit of %d bytes", request.ID, docBytes, request.Index, maxDocBytes)
+		}
+	}
+
+	contentHash := ""
+	if w.Config.Dedupe {
+		var err error
+		if contentHash, err = v1ContentHash(request.Keywords, rawKeywords, request.Source, request.BoostValue); err != nil {
+			return nil, "", fmt.Errorf("hash doc %s: %w", request.ID, err)
+		}
+	}
+
 	sortableID, _ := strconv.ParseInt(request.ID, 10, 64)
 	if sortableID == 0 {
 		sortableID = time.Now().UnixNano()
 	}
 
-	v1Indices[offset].Naive[request.ID] = &V1Doc{
-		ID:         request.ID,
-		SortableID: sortableID,
-		Keywords:   request.Keywords,
-		Source:     request.Source,
-		Index:      request.Index,
-		ModifiedAt: time.Now().Unix(),
+	now := time.Now().Unix()
+
+	result, createdAt := V1ResultCreated, now
+	if existing != nil {
+		if request.OpType == V1OpTypeCreate {
+			return nil, "", fmt.Errorf("%w: doc %s already exists in index %s", ErrVersionConflict, request.ID, request.Index)
+		}
+
+		if contentHash != "" && contentHash == existing.ContentHash {
+			return nil, V1ResultNoop, nil
+		}
+
+		result, createdAt = V1ResultUpdated, existing.CreatedAt
 	}
 
-	return nil
+	return &V1Doc{
+		ID:          request.ID,
+		SortableID:  sortableID,
+		Keywords:    request.Keywords,
+		RawKeywords: rawKeywords,
+		Source:      request.Source,
+		Index:       request.Index,
+		ModifiedAt:  now,
+		CreatedAt:   createdAt,
+		BoostValue:  request.BoostValue,
+		ContentHash: contentHash,
+	}, result, nil
 }
 
-func V1Reset(ctx *gin.Context, index string) string {
-	offset := V1GetIndexMapping(index)
-	if offset < 0 {
-		return "Index not found"
+// full reports whether the index holds MaxDocs docs, the caller must hold the lock
+func (w *v1IndexWrapper) full() bool {
+	return w.Config.MaxDocs > 0 && len(w.Naive) >= w.Config.MaxDocs
+}
+
+// V1Delete removes the doc from the index
+func V1Delete(ctx *gin.Context, index, id string) error {
+	w, unlock, err := v1LockIndex(index, true)
+	if err != nil {
+		return err
 	}
 
-	v1Indices[offset].Lock.Lock()
-	defer v1Indices[offset].Lock.Unlock()
+	if err := w.writable(); err != nil {
+		unlock()
+		return err
+	}
+	doc, found := w.Naive[id]
+	if !found {
+		unlock()
+		return fmt.Errorf("%w: %s in index %s", ErrDocNotFound, id, index)
+	}
+
+	w.touch()
+	w.seqNo++
+	w.remove(id)
+	var evictions *v1Evictions
+	if w.Config.SoftDeleteWindow > 0 {
+		// It only leaves the index once it expires
+		if replaced := w.trashDoc(doc); replaced != nil {
+			evictions = w.evictions(replaced)
+		}
+	} else {
+		evictions = w.evictions(doc)
+	}
+	unlock()
 
-	v1Indices[offset].Naive = make(map[string]*V1Doc)
+	evictions.notify()
 
-	return "OK"
+	v1Emit(&V1ChangeEvent{Type: V1EventDelete, Index: index, ID: id})
+
+	return nil
 }
 
-func V1Peak(ctx *gin.Context, index string) map[string]interface{} {
-	offset := V1GetIndexMapping(index)
-	if offset < 0 {
-		return map[string]interface{}{
-			"message": "Index not found",
-		}
+// V1IndexVersion returns the generation of the index, which changes on every write but not on reads,
+// so it can back an ETag
+func V1IndexVersion(ctx *gin.Context, index string) (int64, error) {
+	w, unlock, err := v1LockIndex(index, false)
+	if err != nil {
+		return 0, err
 	}
+	defer unlock()
 
-	v1Indices[offset].Lock.RLock()
-	defer v1Indices[offset].Lock.RUnlock()
+	return w.seqNo, nil
+}
 
-	return map[string]interface{}{
-		"index":       index,
-		"initialized": v1Indices[offset].Initialized,
-		"total":       len(v1Indices[offset].Naive),
+// V1Reset removes every doc of the index, keeping its config
+func V1Reset(ctx *gin.Context, index string) error {
+	w, unlock, err := v1LockIndex(index, true)
+	if err != nil {
+		return err
 	}
+
+	if err := w.writable(); err != nil {
+		unlock()
+		return err
+	}
+	evictions := w.evictions(w.all()...)
+	w.seqNo++
+	w.reset()
+	unlock()
+
+	evictions.notify()
+
+	v1Emit(&V1ChangeEvent{Type: V1EventReset, Index: index})
+
+	return nil
+}
+
+// V1PeekResult is the overview of an index returned by V1Peak
+type V1PeekResult struct {
+	Index       string `json:"index"`
+	Initialized bool   `json:"initialized"`
+	Total       int    `json:"total"`
+}
+
+// V1Peak returns the overview of the index, it fails with ErrIndexNotFound
+func V1Peak(ctx *gin.Context, index string) (*V1PeekResult, error) {
+	w, unlock, err := v1LockIndex(index, false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return &V1PeekResult{
+		Index:       index,
+		Initialized: w.Initialized,
+		Total:       len(w.Naive),
+	}, nil
 }
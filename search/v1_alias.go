@@ -0,0 +1,74 @@
+package search
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	v1AliasLock = &sync.RWMutex{}
+	// v1Aliases maps an alias to the index queries on it are run against
+	v1Aliases = map[string]string{}
+)
+
+// V1PutAlias points the alias at the index, queries on the alias run against the index.
+// Aliases are read-only, no index can be created under the name of one
+func V1PutAlias(ctx *gin.Context, alias, index string) error {
+	if alias == "" || alias == index {
+		return fmt.Errorf("invalid alias %s", alias)
+	}
+
+	// Held along, so no index is created under the alias in between
+	v1IndexLock.RLock()
+	defer v1IndexLock.RUnlock()
+
+	_, found := v1IndexMapping[alias]
+	v1SpillLock.Lock()
+	_, spilled := v1Spills[alias]
+	v1SpillLock.Unlock()
+	if found || spilled {
+		return fmt.Errorf("alias %s collides with an index", alias)
+	}
+
+	v1AliasLock.Lock()
+	defer v1AliasLock.Unlock()
+
+	v1Aliases[alias] = index
+
+	return nil
+}
+
+// V1DeleteAlias removes the alias, it reports false if the alias doesn't exist
+func V1DeleteAlias(ctx *gin.Context, alias string) bool {
+	v1AliasLock.Lock()
+	defer v1AliasLock.Unlock()
+
+	_, found := v1Aliases[alias]
+	delete(v1Aliases, alias)
+
+	return found
+}
+
+// v1IsAlias reports whether the name is an alias
+func v1IsAlias(name string) bool {
+	v1AliasLock.RLock()
+	defer v1AliasLock.RUnlock()
+
+	_, found := v1Aliases[name]
+
+	return found
+}
+
+// v1ResolveAlias returns the index the name is an alias of, or the name itself
+func v1ResolveAlias(name string) string {
+	v1AliasLock.RLock()
+	defer v1AliasLock.RUnlock()
+
+	if index, found := v1Aliases[name]; found {
+		return index
+	}
+
+	return name
+}
@@ -0,0 +1,127 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// v1Tokenize lowercases the value and splits it on anything but letters and digits,
+// returning the sorted distinct tokens
+func v1Tokenize(value string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	sort.Strings(fields)
+
+	tokens := fields[:0]
+	for i, token := range fields {
+		if i == 0 || token != fields[i-1] {
+			tokens = append(tokens, token)
+		}
+	}
+
+	return tokens
+}
+
+// v1Analyze tokenizes every keyword of the doc, parses the numeric fields of the index and estimates the source,
+// it replaces the derived maps rather than modifying them, so a copy of the doc can be re-analyzed
+func v1Analyze(config V1IndexConfig, doc *V1Doc) {
+	doc.SourceBytes, _ = v1EstimateDocBytes(doc.Source)
+
+	doc.Tokens = make(map[string][]string, len(doc.Keywords))
+	doc.KeywordBytes = 0
+	for k, v := range doc.Keywords {
+		doc.Tokens[k] = v1Tokenize(v)
+		doc.KeywordBytes += len(k) + len(v)
+	}
+
+	doc.KeywordsNum = nil
+	for _, field := range config.NumericFields {
+		v, found := doc.Keywords[field]
+		if !found {
+			continue
+		}
+
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			continue
+		}
+
+		if doc.KeywordsNum == nil {
+			doc.KeywordsNum = make(map[string]float64, len(config.NumericFields))
+		}
+		doc.KeywordsNum[field] = n
+	}
+}
+
+// v1NumericValue returns the pre-parsed value of a numeric field, other fields are parsed on the fly
+func v1NumericValue(doc *V1Doc, field string) (float64, error) {
+	if n, found := doc.KeywordsNum[field]; found {
+		return n, nil
+	}
+
+	v, found := doc.Keywords[field]
+	if !found {
+		return 0, fmt.Errorf("doc %s has no field %s", doc.ID, field)
+	}
+
+	n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil {
+		return 0, fmt.Errorf("doc %s field %s is not numeric: %q", doc.ID, field, v)
+	}
+
+	return n, nil
+}
+
+// v1HasToken reports whether the sorted tokens contain the term
+func v1HasToken(tokens []string, term string) bool {
+	i := sort.SearchStrings(tokens, term)
+	return i < len(tokens) && tokens[i] == term
+}
+
+// v1CountTerms counts the terms the field's tokens contain, terms are analyzed like the values
+// and a term made of several tokens needs all of them, a term also counts if one of its synonyms does
+func v1CountTerms(doc *V1Doc, field string, terms []string, synonyms map[string][]string) int {
+	tokens, found := doc.Tokens[field]
+	if !found {
+		return 0
+	}
+
+	count := 0
+	for _, term := range terms {
+		if v1HasTerm(tokens, term) {
+			count++
+			continue
+		}
+
+		for _, synonym := range synonyms[v1SynonymKey(term)] {
+			if v1HasTerm(tokens, synonym) {
+				count++
+				break
+			}
+		}
+	}
+
+	return count
+}
+
+// v1HasTerm reports whether the tokens contain every token of the term
+func v1HasTerm(tokens []string, term string) bool {
+	for _, token := range v1Tokenize(term) {
+		if !v1HasToken(tokens, token) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// v1HasAllTerms reports whether the field's tokens contain every term
+func v1HasAllTerms(doc *V1Doc, field string, terms []string, synonyms map[string][]string) bool {
+	_, found := doc.Tokens[field]
+	return found && v1CountTerms(doc, field, terms, synonyms) == len(terms)
+}
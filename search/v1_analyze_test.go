@@ -0,0 +1,135 @@
+package search
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func v1Float(v float64) *float64 {
+	return &v
+}
+
+func TestV1NumericFields(t *testing.T) {
+	index := v1TestIndex(t, "numeric-fields")
+
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{NumericFields: []string{"price"}}))
+
+	for i, price := range []string{"5", "12.5", "100", "n/a"} {
+		assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: fmt.Sprint(i + 1), Keywords: map[string]string{"price": price}}))
+	}
+
+	offset := V1GetIndexMapping(index)
+	assert.Equal(t, map[string]float64{"price": 12.5}, v1Indices[offset].Naive["2"].KeywordsNum)
+	assert.Nil(t, v1Indices[offset].Naive["4"].KeywordsNum)
+
+	response, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{
+		Ranges:     map[string]*V1Range{"price": {Gte: v1Float(5), Lt: v1Float(100)}},
+		ScriptSort: "price",
+	}})
+	if assert.Equal(t, 2, response.Hits.Total) {
+		assert.Equal(t, "2", response.Hits.Hits[0].ID)
+		assert.Equal(t, "1", response.Hits.Hits[1].ID)
+	}
+
+	// Ranges read the pre-parsed value rather than the string
+	v1Indices[offset].Naive["1"].KeywordsNum["price"] = 1000
+	response, _ = V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{
+		Ranges: map[string]*V1Range{"price": {Gt: v1Float(500)}},
+	}})
+	if assert.Equal(t, 1, response.Hits.Total) {
+		assert.Equal(t, "1", response.Hits.Hits[0].ID)
+	}
+
+	// Mapping a field later re-analyzes the stored docs
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{}))
+	assert.Nil(t, v1Indices[offset].Naive["2"].KeywordsNum)
+
+	response, _ = V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{
+		Ranges: map[string]*V1Range{"price": {Lte: v1Float(12.5)}},
+	}})
+	assert.Equal(t, 2, response.Hits.Total)
+}
+
+func TestV1NumericFieldsSort(t *testing.T) {
+	index := v1TestIndex(t, "numeric-fields-sort")
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{NumericFields: []string{"price"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"price": "9"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2", Keywords: map[string]string{"price": "10"}}))
+
+	// "10" sorts above "9" by value, not below it by string
+	for mode, expected := range map[string][]string{"desc": {"2", "1"}, "asc": {"1", "2"}} {
+		response, err := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{SortBys: "price", SortMode: mode}})
+		assert.Nil(t, err)
+		ids := make([]string, 0)
+		for _, hit := range response.Hits.Hits {
+			ids = append(ids, hit.ID)
+		}
+		assert.Equal(t, expected, ids, mode)
+	}
+
+	// The values that don't parse come after the numbers in both modes, ordered as strings among themselves
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "3", Keywords: map[string]string{"price": "5a"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "4", Keywords: map[string]string{"price": "10b"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "5"}))
+	for mode, expected := range map[string][]string{"desc": {"2", "1", "3", "4", "5"}, "asc": {"1", "2", "5", "4", "3"}} {
+		response, err := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{SortBys: "price", SortMode: mode}})
+		assert.Nil(t, err)
+		ids := make([]string, 0)
+		for _, hit := range response.Hits.Hits {
+			ids = append(ids, hit.ID)
+		}
+		assert.Equal(t, expected, ids, mode)
+	}
+}
+
+func BenchmarkV1Range(b *testing.B) {
+	for _, mapped := range []bool{false, true} {
+		index := fmt.Sprintf("bench-range-%v", mapped)
+
+		config := V1IndexConfig{}
+		if mapped {
+			config.NumericFields = []string{"price"}
+		}
+		V1SetIndexConfig(nil, index, config)
+
+		for i := 0; i < 10000; i++ {
+			V1Put(nil, &V1Request{Index: index, ID: fmt.Sprint(i + 1), Keywords: map[string]string{"price": fmt.Sprintf("%d.%02d", i%500, i%100)}})
+		}
+
+		b.Run(fmt.Sprintf("numeric_fields=%v", mapped), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{
+					Ranges: map[string]*V1Range{"price": {Gte: v1Float(100), Lt: v1Float(101)}},
+				}})
+			}
+		})
+
+		v1DropIndex(index)
+	}
+}
+
+func BenchmarkV1NumericSort(b *testing.B) {
+	for _, mapped := range []bool{false, true} {
+		index := fmt.Sprintf("bench-numeric-sort-%v", mapped)
+
+		config := V1IndexConfig{}
+		if mapped {
+			config.NumericFields = []string{"price"}
+		}
+		V1SetIndexConfig(nil, index, config)
+
+		for i := 0; i < 10000; i++ {
+			V1Put(nil, &V1Request{Index: index, ID: fmt.Sprint(i + 1), Keywords: map[string]string{"price": fmt.Sprintf("%d.%02d", i%500, i%100)}})
+		}
+
+		b.Run(fmt.Sprintf("numeric_fields=%v", mapped), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{SortBys: "price", SortMode: "asc"}})
+			}
+		})
+
+		v1DropIndex(index)
+	}
+}
@@ -0,0 +1,132 @@
+package search
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// v1BackgroundLock is held for reading by every background tick, so pausing waits for the in-flight ones
+	v1BackgroundLock = &sync.RWMutex{}
+	v1Paused         bool
+
+	v1WorkersLock = &sync.Mutex{}
+	v1Workers     = make(map[*v1Worker]struct{})
+)
+
+// v1Worker runs a tick of background work periodically, like the sweepers and the replica refreshers
+type v1Worker struct {
+	quit chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// v1StartWorker runs tick every interval until the returned stop or V1Shutdown is called
+func v1StartWorker(interval time.Duration, tick func()) (*v1Worker, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid worker interval %s", interval)
+	}
+
+	w := &v1Worker{
+		quit: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	v1WorkersLock.Lock()
+	v1Workers[w] = struct{}{}
+	v1WorkersLock.Unlock()
+
+	go func() {
+		defer close(w.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-w.quit:
+				return
+			case <-ticker.C:
+				v1Background(tick)
+			}
+		}
+	}()
+
+	return w, nil
+}
+
+// stop stops the worker and waits for an in-flight tick to finish, it may be called more than once
+func (w *v1Worker) stop() {
+	w.once.Do(func() {
+		v1WorkersLock.Lock()
+		delete(v1Workers, w)
+		v1WorkersLock.Unlock()
+
+		close(w.quit)
+	})
+	<-w.done
+}
+
+// v1Background runs a tick of background work unless it's paused
+func v1Background(tick func()) {
+	v1BackgroundLock.RLock()
+	defer v1BackgroundLock.RUnlock()
+
+	if v1Paused {
+		return
+	}
+
+	tick()
+}
+
+// V1Pause halts the sweepers, replica refreshers and auto snapshots until V1Resume, it waits for the in-flight
+// work to finish, the schedules are kept and the ticks in between are skipped
+func V1Pause() {
+	v1BackgroundLock.Lock()
+	v1Paused = true
+	v1BackgroundLock.Unlock()
+}
+
+// V1Resume lets the background work paused by V1Pause run again
+func V1Resume() {
+	v1BackgroundLock.Lock()
+	v1Paused = false
+	v1BackgroundLock.Unlock()
+}
+
+// V1Shutdown stops every sweeper, replica refresher and auto snapshot, then writes a final snapshot of each
+// auto snapshotted index, it returns the first error of the final snapshots
+func V1Shutdown(ctx *gin.Context) error {
+	v1WorkersLock.Lock()
+	workers := make([]*v1Worker, 0, len(v1Workers))
+	for w := range v1Workers {
+		workers = append(workers, w)
+	}
+	v1WorkersLock.Unlock()
+
+	for _, w := range workers {
+		w.stop()
+	}
+
+	v1AutoSnapshotLock.Lock()
+	autos := make(map[string]*v1AutoSnapshot, len(v1AutoSnapshots))
+	for index, auto := range v1AutoSnapshots {
+		autos[index] = auto
+	}
+	v1AutoSnapshotLock.Unlock()
+
+	var first error
+	for index, auto := range autos {
+		V1DisableAutoSnapshot(ctx, index)
+
+		// The final snapshot is written even while paused
+		if err := V1SnapshotToFile(ctx, index, V1SnapshotPath(auto.dir, index)); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
@@ -0,0 +1,93 @@
+package search
+
+import (
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1PauseResume(t *testing.T) {
+	index := v1TestIndex(t, "background-pause")
+	dir := t.TempDir()
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{SoftDeleteWindow: time.Nanosecond}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1"}))
+	assert.Nil(t, V1Delete(nil, index, "1"))
+
+	trashed := func() int {
+		offset := V1GetIndexMapping(index)
+		v1Indices[offset].Lock.RLock()
+		defer v1Indices[offset].Lock.RUnlock()
+		return len(v1Indices[offset].trash)
+	}
+
+	V1Pause()
+	defer V1Resume()
+
+	stop, err := V1StartSweeper(time.Millisecond)
+	assert.Nil(t, err)
+	defer stop()
+	assert.Nil(t, V1EnableAutoSnapshot(nil, index, dir, time.Millisecond))
+	defer V1DisableAutoSnapshot(nil, index)
+
+	// Nothing runs while paused
+	path := V1SnapshotPath(dir, index)
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, 1, trashed())
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	// The schedules pick up again on resume
+	V1Resume()
+	assert.Eventually(t, func() bool {
+		return trashed() == 0
+	}, time.Second, 5*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestV1Shutdown(t *testing.T) {
+	index := v1TestIndex(t, "background-shutdown")
+	restored := v1TestIndex(t, "background-shutdown-restored")
+	dir := t.TempDir()
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1"}))
+
+	_, err := V1StartSweeper(time.Hour)
+	assert.Nil(t, err)
+	assert.Nil(t, V1EnableAutoSnapshot(nil, index, dir, time.Hour))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2"}))
+
+	// The final snapshot is written even while paused
+	V1Pause()
+	defer V1Resume()
+	assert.Nil(t, V1Shutdown(nil))
+
+	v1WorkersLock.Lock()
+	assert.Empty(t, v1Workers)
+	v1WorkersLock.Unlock()
+	v1AutoSnapshotLock.Lock()
+	assert.Empty(t, v1AutoSnapshots)
+	v1AutoSnapshotLock.Unlock()
+
+	assert.Nil(t, V1RestoreFromFile(nil, restored, V1SnapshotPath(dir, index)))
+	if peek, err := V1Peak(nil, restored); assert.Nil(t, err) {
+		assert.Equal(t, 2, peek.Total)
+	}
+}
+
+func TestV1WorkerInvalidInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		_, err := V1StartSweeper(interval)
+		assert.NotNil(t, err)
+		_, err = V1StartReplicaRefresher("background-replica", interval, func() (io.ReadCloser, error) {
+			return nil, os.ErrNotExist
+		})
+		assert.NotNil(t, err)
+		_, err = V1StartMemoryWatcher(interval)
+		assert.NotNil(t, err)
+	}
+}
@@ -0,0 +1,80 @@
+package search
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	V1ResultCreated = "created"
+	V1ResultUpdated = "updated"
+	V1ResultDeleted = "deleted"
+	V1ResultError   = "error"
+	// V1ResultNoop is the result of a put skipped by dedupe
+	V1ResultNoop = "noop"
+)
+
+// V1BulkResponse is the response of search v1 bulk
+type V1BulkResponse struct {
+	Took    int64         `json:"took"`
+	Created int           `json:"created"`
+	Updated int           `json:"updated"`
+	Deleted int           `json:"deleted"`
+	Noops   int           `json:"noops"`
+	Errors  int           `json:"errors"`
+	Items   []*V1BulkItem `json:"items"`
+}
+
+// V1BulkItem is the result of a single request of search v1 bulk
+type V1BulkItem struct {
+	Index  string `json:"_index"`
+	ID     string `json:"_id"`
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// V1Bulk puts the docs one by one, a failed item doesn't stop the following ones
+func V1Bulk(ctx *gin.Context, requests []*V1Request) *V1BulkResponse {
+	start := time.Now()
+
+	response := &V1BulkResponse{
+		Items: make([]*V1BulkItem, 0, len(requests)),
+	}
+
+	for _, request := range requests {
+		item := &V1BulkItem{Index: request.Index}
+
+		result, err := v1Put(ctx, request)
+		item.ID = request.ID
+
+		response.add(item, result, err)
+	}
+
+	response.Took = time.Since(start).Milliseconds()
+
+	return response
+}
+
+// add records the result of the item, or its error
+func (r *V1BulkResponse) add(item *V1BulkItem, result string, err error) {
+	if err != nil {
+		item.Result = V1ResultError
+		item.Error = err.Error()
+		r.Errors++
+	} else {
+		item.Result = result
+		switch result {
+		case V1ResultCreated:
+			r.Created++
+		case V1ResultDeleted:
+			r.Deleted++
+		case V1ResultNoop:
+			r.Noops++
+		default:
+			r.Updated++
+		}
+	}
+
+	r.Items = append(r.Items, item)
+}
@@ -0,0 +1,39 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1Bulk(t *testing.T) {
+	index := v1TestIndex(t, "bulk")
+
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{MaxDocBytes: 64}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"name": "a"}}))
+
+	response := V1Bulk(nil, []*V1Request{
+		{Index: index, ID: "1", Keywords: map[string]string{"name": "b"}},
+		{Index: index, ID: "2", Keywords: map[string]string{"name": "c"}},
+		{Index: index, ID: "3", Keywords: map[string]string{"name": strings.Repeat("d", 64)}},
+		{Index: index, Keywords: map[string]string{"name": "e"}},
+	})
+
+	assert.Equal(t, 2, response.Created)
+	assert.Equal(t, 1, response.Updated)
+	assert.Equal(t, 1, response.Errors)
+
+	if assert.Len(t, response.Items, 4) {
+		assert.Equal(t, &V1BulkItem{Index: index, ID: "1", Result: V1ResultUpdated}, response.Items[0])
+		assert.Equal(t, &V1BulkItem{Index: index, ID: "2", Result: V1ResultCreated}, response.Items[1])
+		assert.Equal(t, V1ResultError, response.Items[2].Result)
+		assert.NotEmpty(t, response.Items[2].Error)
+		assert.Equal(t, V1ResultCreated, response.Items[3].Result)
+		assert.NotEmpty(t, response.Items[3].ID)
+	}
+
+	if peek, err := V1Peak(nil, index); assert.Nil(t, err) {
+		assert.Equal(t, 3, peek.Total)
+	}
+}
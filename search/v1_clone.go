@@ -0,0 +1,95 @@
+package search
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// V1Clone creates dest with a deep copy of the config, synonyms and docs of source, and returns the number of
+// docs cloned. It fails with ErrIndexExists if dest is already there
+func V1Clone(ctx *gin.Context, source, dest string) (int, error) {
+	src, unlock, err := v1LockIndex(source, false)
+	if err != nil {
+		return 0, err
+	}
+
+	config := v1CopyConfig(src.Config)
+	// Replaced as a whole and never modified, so it can be shared
+	synonyms := src.synonyms
+	docs := make([]*V1Doc, 0, len(src.Naive))
+	for _, doc := range src.Naive {
+		clone := v1CopyDoc(doc)
+		clone.Index = dest
+		docs = append(docs, clone)
+	}
+	unlock()
+
+	// Checked and created at once, so a concurrent create of dest isn't overwritten
+	err = v1CreateIndex(ctx, dest, func(w *v1IndexWrapper) {
+		w.Config = config
+		w.synonyms = synonyms
+		w.load(docs)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(docs), nil
+}
+
+func v1CopyConfig(config V1IndexConfig) V1IndexConfig {
+	if config.Normalizers != nil {
+		normalizers := make(map[string][]string, len(config.Normalizers))
+		for k, v := range config.Normalizers {
+			normalizers[k] = append([]string(nil), v...)
+		}
+		config.Normalizers = normalizers
+	}
+
+	config.NumericFields = append([]string(nil), config.NumericFields...)
+
+	return config
+}
+
+func v1CopyDoc(doc *V1Doc) *V1Doc {
+	clone := *doc
+
+	if doc.Keywords != nil {
+		clone.Keywords = make(map[string]string, len(doc.Keywords))
+		for k, v := range doc.Keywords {
+			clone.Keywords[k] = v
+		}
+	}
+
+	if doc.RawKeywords != nil {
+		clone.RawKeywords = make(map[string]string, len(doc.RawKeywords))
+		for k, v := range doc.RawKeywords {
+			clone.RawKeywords[k] = v
+		}
+	}
+
+	if doc.Source != nil {
+		clone.Source = v1CopyValue(doc.Source).(map[string]interface{})
+	}
+
+	return &clone
+}
+
+// v1CopyValue deep copies the maps and slices a decoded JSON value is made of
+func v1CopyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		clone := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			clone[k] = v1CopyValue(item)
+		}
+		return clone
+	case []interface{}:
+		clone := make([]interface{}, len(v))
+		for i, item := range v {
+			clone[i] = v1CopyValue(item)
+		}
+		return clone
+	default:
+		return v
+	}
+}
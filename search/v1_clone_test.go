@@ -0,0 +1,62 @@
+package search
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1Clone(t *testing.T) {
+	source, dest := v1TestIndex(t, "clone-source"), v1TestIndex(t, "clone-dest")
+
+	assert.Nil(t, V1SetIndexConfig(nil, source, V1IndexConfig{
+		Normalizers: map[string][]string{"name": {V1NormalizerLowercase}},
+	}))
+	assert.Nil(t, V1Put(nil, &V1Request{
+		Index:    source,
+		ID:       "1",
+		Keywords: map[string]string{"name": "a"},
+		Source:   map[string]interface{}{"tags": []interface{}{"x", map[string]interface{}{"y": "z"}}},
+	}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: source, ID: "2", Keywords: map[string]string{"name": "b"}}))
+	assert.Nil(t, V1PutSynonyms(nil, source, [][]string{{"a", "alpha"}}))
+
+	cloned, err := V1Clone(nil, source, dest)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, cloned)
+
+	// The existing dest is left as it is
+	assert.Nil(t, V1Put(nil, &V1Request{Index: dest, ID: "extra"}))
+	_, err = V1Clone(nil, source, dest)
+	assert.True(t, errors.Is(err, ErrIndexExists))
+	assert.Nil(t, V1Delete(nil, dest, "extra"))
+
+	// The synonyms come along
+	response, _ := V1(nil, &V1Request{Index: dest, Query: &V1RequestQuery{Filters: map[string]string{"name": "alpha"}}})
+	assert.Equal(t, 1, response.Hits.Total)
+
+	// The config comes along
+	assert.Nil(t, V1Put(nil, &V1Request{Index: dest, ID: "3", Keywords: map[string]string{"name": "C"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: source, ID: "4", Keywords: map[string]string{"name": "d"}}))
+
+	if peek, err := V1Peak(nil, dest); assert.Nil(t, err) {
+		assert.Equal(t, 3, peek.Total)
+	}
+	if peek, err := V1Peak(nil, source); assert.Nil(t, err) {
+		assert.Equal(t, 3, peek.Total)
+	}
+
+	response, _ = V1(nil, &V1Request{Index: dest, Query: &V1RequestQuery{Filters: map[string]string{"name": "c"}}})
+	assert.Equal(t, 1, response.Hits.Total)
+
+	// Nested source values are not shared
+	offset, destOffset := V1GetIndexMapping(source), V1GetIndexMapping(dest)
+	v1Indices[destOffset].Naive["1"].Source["tags"].([]interface{})[1].(map[string]interface{})["y"] = "changed"
+	assert.Equal(t, "z", v1Indices[offset].Naive["1"].Source["tags"].([]interface{})[1].(map[string]interface{})["y"])
+
+	// Secondary indexes are rebuilt for the clone
+	recent, _ := V1Recent(nil, dest, 10)
+	assert.Len(t, recent, 3)
+	assert.Equal(t, dest, recent[0].Index)
+}
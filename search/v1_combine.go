@@ -0,0 +1,43 @@
+package search
+
+import "fmt"
+
+const (
+	V1CombineSum      = "sum"
+	V1CombineMax      = "max"
+	V1CombineMultiply = "multiply"
+	V1CombineWeighted = "weighted"
+)
+
+// v1ValidateCombiner rejects unknown combiners and weights outside [0, 1]
+func v1ValidateCombiner(combiner string, weight float64) error {
+	switch combiner {
+	case "", V1CombineSum, V1CombineMax, V1CombineMultiply:
+	case V1CombineWeighted:
+		if weight < 0 || weight > 1 {
+			return fmt.Errorf("invalid external score weight %g", weight)
+		}
+	default:
+		return fmt.Errorf("unknown score combiner %s", combiner)
+	}
+
+	return nil
+}
+
+// v1CombineScore blends the keyword score with the external one, "weighted" gives weight to
+// the external score and the rest to the keyword score
+func v1CombineScore(combiner string, weight, score, external float64) float64 {
+	switch combiner {
+	case V1CombineMax:
+		if external > score {
+			return external
+		}
+		return score
+	case V1CombineMultiply:
+		return score * external
+	case V1CombineWeighted:
+		return (1-weight)*score + weight*external
+	}
+
+	return score + external
+}
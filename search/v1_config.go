@@ -0,0 +1,124 @@
+package search
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// V1IndexConfig is the per-index config of search v1
+type V1IndexConfig struct {
+	// MaxDocBytes limits the serialized size of a doc's source, 0 means unlimited
+	MaxDocBytes int64 `json:"max_doc_bytes,omitempty"`
+	// CopyOnWrite lets queries scan an immutable snapshot instead of holding the read lock,
+	// at the cost of copying the doc list on every write
+	CopyOnWrite bool `json:"copy_on_write,omitempty"`
+	// Normalizers maps a keyword field to the normalizers applied to its values at index time,
+	// filters on the field are normalized the same way at query time
+	Normalizers map[string][]string `json:"normalizers,omitempty"`
+	// MaxDocs caps the number of docs in the index, 0 means unlimited
+	MaxDocs int `json:"max_docs,omitempty"`
+	// MaxDocsPolicy decides what a put does on a full index, "reject" (the default) or "evict_oldest",
+	// which evicts the least recently modified doc
+	MaxDocsPolicy string `json:"max_docs_policy,omitempty"`
+	// NumericFields are parsed into KeywordsNum at index time, so ranges and numeric sorts don't parse per query
+	NumericFields []string `json:"numeric_fields,omitempty"`
+	// Shards is the number of shards the docs are assigned to by consistent hashing, 0 or 1 means unsharded
+	Shards int `json:"shards,omitempty"`
+	// Collation orders the SortBys keywords, e.g. "ZH-HANS_CI", empty means byte order
+	Collation string `json:"collation,omitempty"`
+	// SoftDeleteWindow keeps deleted docs restorable by V1Undelete for the duration, 0 deletes for good
+	SoftDeleteWindow time.Duration `json:"soft_delete_window,omitempty"`
+	// Folding applies the fold normalizer to every keyword field after the field's own normalizers
+	Folding bool `json:"folding,omitempty"`
+	// OnEvict is called with a copy of every doc leaving the index by a delete, a reset, a soft delete expiry,
+	// MaxDocs eviction or the eviction of the whole index, outside of any lock. It's not persisted in snapshots
+	OnEvict func(*V1Doc) `json:"-"`
+	// Dedupe skips a put whose content hashes the same as the stored doc's, V1Put returns ErrNotModified for it
+	Dedupe bool `json:"dedupe,omitempty"`
+}
+
+const (
+	V1MaxDocsPolicyReject      = "reject"
+	V1MaxDocsPolicyEvictOldest = "evict_oldest"
+)
+
+func (c V1IndexConfig) validate() error {
+	if c.SoftDeleteWindow < 0 {
+		return fmt.Errorf("invalid soft delete window %s", c.SoftDeleteWindow)
+	}
+
+	if c.Shards < 0 {
+		return fmt.Errorf("invalid shard count %d", c.Shards)
+	}
+
+	switch c.MaxDocsPolicy {
+	case "", V1MaxDocsPolicyReject, V1MaxDocsPolicyEvictOldest:
+	default:
+		return fmt.Errorf("unknown max docs policy %s", c.MaxDocsPolicy)
+	}
+
+	return v1ValidateNormalizers(c.Normalizers)
+}
+
+// V1SetIndexConfig creates the index if needed and replaces its config
+func V1SetIndexConfig(ctx *gin.Context, index string, config V1IndexConfig) error {
+	if err := config.validate(); err != nil {
+		return err
+	}
+
+	w, unlock, err := v1CreateAndLockIndex(ctx, index)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := w.writable(); err != nil {
+		return err
+	}
+
+	// The analysis depends on the config, so the docs are re-analyzed as copies,
+	// concurrent snapshot readers may still hold the current ones
+	w.Config = config
+	w.load(w.copies())
+
+	return nil
+}
+
+// V1GetIndexConfig returns the config of the index
+func V1GetIndexConfig(ctx *gin.Context, index string) (V1IndexConfig, error) {
+	w, unlock, err := v1LockIndex(index, false)
+	if err != nil {
+		return V1IndexConfig{}, err
+	}
+	defer unlock()
+
+	return w.Config, nil
+}
+
+// v1EstimateDocBytes estimates the size of a doc by its serialized source
+func v1EstimateDocBytes(source map[string]interface{}) (int64, error) {
+	raw, err := json.Marshal(source)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(raw)), nil
+}
+
+// v1ContentHash hashes what a put stores of a doc, the serialized maps have their keys sorted
+// so equal content always hashes the same
+func v1ContentHash(keywords, rawKeywords map[string]string, source map[string]interface{}, boostValue float64) (string, error) {
+	raw, err := json.Marshal([]interface{}{keywords, rawKeywords, source, boostValue})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(raw)
+
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,126 @@
+package search
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1MaxDocBytes(t *testing.T) {
+	index := v1TestIndex(t, "max-doc-bytes")
+
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{MaxDocBytes: 32}))
+
+	// {"hello":"01234567890123456789"} is exactly 32 bytes
+	err := V1Put(nil, &V1Request{
+		Index:    index,
+		ID:       "1",
+		Keywords: map[string]string{"hello": "01234567890123456789"},
+	})
+	assert.Nil(t, err)
+
+	// One byte over the limit
+	err = V1Put(nil, &V1Request{
+		Index:    index,
+		ID:       "2",
+		Keywords: map[string]string{"hello": "012345678901234567890"},
+	})
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "exceeds index max-doc-bytes limit")
+	}
+
+	if peek, err := V1Peak(nil, index); assert.Nil(t, err) {
+		assert.Equal(t, 1, peek.Total)
+	}
+}
+
+func TestV1MaxDocsReject(t *testing.T) {
+	index := v1TestIndex(t, "max-docs-reject")
+
+	assert.NotNil(t, V1SetIndexConfig(nil, index, V1IndexConfig{MaxDocs: 2, MaxDocsPolicy: "evict_newest"}))
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{MaxDocs: 2}))
+
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1"}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2"}))
+	assert.NotNil(t, V1Put(nil, &V1Request{Index: index, ID: "3"}))
+
+	// Updating an existing doc is still allowed on a full index
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2", Keywords: map[string]string{"v": "2"}}))
+
+	if peek, err := V1Peak(nil, index); assert.Nil(t, err) {
+		assert.Equal(t, 2, peek.Total)
+	}
+}
+
+func TestV1MaxDocsEvictOldest(t *testing.T) {
+	index := v1TestIndex(t, "max-docs-evict-oldest")
+
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{MaxDocs: 2, MaxDocsPolicy: V1MaxDocsPolicyEvictOldest}))
+
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1"}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2"}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "3"}))
+
+	response, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{SortMode: "asc"}})
+	if assert.Equal(t, 2, response.Hits.Total) {
+		assert.Equal(t, "2", response.Hits.Hits[0].ID)
+		assert.Equal(t, "3", response.Hits.Hits[1].ID)
+	}
+
+	// An update makes the doc the most recently modified, so the other one goes first
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2", Keywords: map[string]string{"v": "2"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "4"}))
+	response, _ = V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{SortMode: "asc"}})
+	if assert.Equal(t, 2, response.Hits.Total) {
+		assert.Equal(t, "2", response.Hits.Hits[0].ID)
+		assert.Equal(t, "4", response.Hits.Hits[1].ID)
+	}
+}
+
+func TestV1Dedupe(t *testing.T) {
+	index := v1TestIndex(t, "dedupe")
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{Dedupe: true, Normalizers: map[string][]string{"name": {V1NormalizerLowercase}}}))
+
+	events := 0
+	cancel := V1Watch(func(event *V1ChangeEvent) {
+		if event.Index == index {
+			events++
+		}
+	})
+	defer cancel()
+
+	put := func(name string, source map[string]interface{}) error {
+		return V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"name": name}, Source: source})
+	}
+
+	assert.Nil(t, put("Apple", map[string]interface{}{"n": 1}))
+	offset := V1GetIndexMapping(index)
+	stored := v1Indices[offset].Naive["1"]
+	assert.NotEmpty(t, stored.ContentHash)
+
+	// Identical content is a no-op, the stored doc and the seq no stay
+	err := put("Apple", map[string]interface{}{"n": 1})
+	assert.True(t, errors.Is(err, ErrNotModified))
+	assert.Same(t, stored, v1Indices[offset].Naive["1"])
+	assert.Equal(t, 1, events)
+
+	// Changed content, even only the original case of a keyword, updates
+	assert.Nil(t, put("Apple", map[string]interface{}{"n": 2}))
+	assert.Nil(t, put("APPLE", map[string]interface{}{"n": 2}))
+	assert.Equal(t, 3, events)
+	assert.Equal(t, int64(3), v1Indices[offset].Naive["1"].SeqNo)
+
+	bulk := V1Bulk(nil, []*V1Request{
+		{Index: index, ID: "1", Keywords: map[string]string{"name": "APPLE"}, Source: map[string]interface{}{"n": 2}},
+		{Index: index, ID: "2"},
+	})
+	assert.Equal(t, 1, bulk.Noops)
+	assert.Equal(t, 1, bulk.Created)
+	assert.Equal(t, V1ResultNoop, bulk.Items[0].Result)
+
+	// Without dedupe every put writes
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{}))
+	assert.Nil(t, put("APPLE", map[string]interface{}{"n": 2}))
+	assert.Empty(t, v1Indices[offset].Naive["1"].ContentHash)
+}
@@ -0,0 +1,109 @@
+package search
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	V1DecayLinear = "linear"
+	V1DecayExp    = "exp"
+	V1DecayGauss  = "gauss"
+)
+
+// V1DecayFieldModifiedAt is the default field of a decay, the last write time of the doc
+const V1DecayFieldModifiedAt = "_modified_at"
+
+// V1DecayFieldCreatedAt decays by the first write time of the doc
+const V1DecayFieldCreatedAt = "_created_at"
+
+// V1Decay lowers the score of the docs by their age, a doc Offset old or newer keeps its score,
+// one Scale older than that has it halved and the older ones decay by Func further
+type V1Decay struct {
+	// Field holds the date, "_modified_at" (the default), "_created_at", or a keyword with unix seconds or
+	// an RFC 3339 date. A doc without a valid date keeps its score
+	Field  string        `json:"field,omitempty"`
+	Scale  time.Duration `json:"scale"`
+	Offset time.Duration `json:"offset,omitempty"`
+	// Func is "linear" down to 0 at twice the Scale, "exp" (the default) or "gauss"
+	Func string `json:"func,omitempty"`
+}
+
+func (d *V1Decay) validate() error {
+	if d == nil {
+		return nil
+	}
+
+	if d.Scale <= 0 {
+		return fmt.Errorf("invalid decay scale %s", d.Scale)
+	}
+
+	if d.Offset < 0 {
+		return fmt.Errorf("invalid decay offset %s", d.Offset)
+	}
+
+	switch d.Func {
+	case "", V1DecayLinear, V1DecayExp, V1DecayGauss:
+		return nil
+	default:
+		return fmt.Errorf("unknown decay func %s", d.Func)
+	}
+}
+
+// factor returns what the score of the doc is multiplied by at now, in [0, 1], 1 for a nil decay
+func (d *V1Decay) factor(doc *V1Doc, now time.Time) float64 {
+	if d == nil {
+		return 1
+	}
+
+	at, ok := v1DecayTime(doc, d.Field)
+	if !ok {
+		return 1
+	}
+
+	age := now.Sub(at) - d.Offset
+	if age <= 0 {
+		return 1
+	}
+
+	x := float64(age) / float64(d.Scale)
+	switch d.Func {
+	case V1DecayLinear:
+		return math.Max(0, 1-x/2)
+	case V1DecayGauss:
+		return math.Pow(0.5, x*x)
+	default:
+		return math.Pow(0.5, x)
+	}
+}
+
+// v1DecayTime reads the date of the doc a decay is computed from
+func v1DecayTime(doc *V1Doc, field string) (time.Time, bool) {
+	switch field {
+	case "", V1DecayFieldModifiedAt:
+		return time.Unix(doc.ModifiedAt, 0), doc.ModifiedAt > 0
+	case V1DecayFieldCreatedAt:
+		return time.Unix(doc.CreatedAt, 0), doc.CreatedAt > 0
+	}
+
+	if n, found := doc.KeywordsNum[field]; found {
+		return time.Unix(int64(n), 0), true
+	}
+
+	v, found := doc.Keywords[field]
+	if !found {
+		return time.Time{}, false
+	}
+
+	v = strings.TrimSpace(v)
+	if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(n, 0), true
+	}
+
+	at, err := time.Parse(time.RFC3339, v)
+
+	return at, err == nil
+}
@@ -0,0 +1,89 @@
+package search
+
+import "regexp"
+
+// V1EchoedQuery is the query as it was executed, after alias resolution, normalization and defaulting,
+// with the regexes given as their source
+type V1EchoedQuery struct {
+	Index           string              `json:"index"`
+	RawAnds         []string            `json:"raw,omitempty"`
+	RawOrs          []string            `json:"raw_ors,omitempty"`
+	RegsAnd         map[string]string   `json:"regs_and,omitempty"`
+	RegsOr          map[string]string   `json:"regs_or,omitempty"`
+	AnyField        string              `json:"any_field,omitempty"`
+	MultiMatch      *V1EchoedMultiMatch `json:"multi_match,omitempty"`
+	Filters         map[string]string   `json:"filters,omitempty"`
+	SinceSeqNo      int64               `json:"since_seq_no,omitempty"`
+	Ranges          map[string]*V1Range `json:"ranges,omitempty"`
+	TermsAll        map[string][]string `json:"terms_all,omitempty"`
+	Terms           map[string][]string `json:"terms,omitempty"`
+	DefaultOperator string              `json:"default_operator"`
+	ScriptSort      string              `json:"script_sort,omitempty"`
+	SortMode        string              `json:"sort_mode"`
+	SortBys         string              `json:"sort_bys,omitempty"`
+	Collation       string              `json:"collation,omitempty"`
+	IDs             []string            `json:"ids,omitempty"`
+	GeoDistance     *V1GeoDistance      `json:"geo_distance,omitempty"`
+	GeoSort         *V1GeoSort          `json:"geo_sort,omitempty"`
+}
+
+// V1EchoedMultiMatch is a V1MultiMatch with its pattern given as source
+type V1EchoedMultiMatch struct {
+	Pattern string   `json:"pattern"`
+	Fields  []string `json:"fields"`
+}
+
+// v1EchoQuery renders the normalized query run against the index
+func v1EchoQuery(index string, query *V1RequestQuery) *V1EchoedQuery {
+	echoed := &V1EchoedQuery{
+		Index:           index,
+		RawAnds:         query.RawAnds,
+		RawOrs:          query.RawOrs,
+		RegsAnd:         v1RegStrings(query.RegsAnd),
+		RegsOr:          v1RegStrings(query.RegsOr),
+		AnyField:        v1RegString(query.AnyField),
+		Filters:         query.Filters,
+		SinceSeqNo:      query.SinceSeqNo,
+		Ranges:          query.Ranges,
+		TermsAll:        query.TermsAll,
+		Terms:           query.Terms,
+		DefaultOperator: V1OperatorOr,
+		ScriptSort:      query.ScriptSort,
+		SortMode:        "desc",
+		SortBys:         query.SortBys,
+		Collation:       query.Collation,
+		IDs:             query.IDs,
+		GeoDistance:     query.GeoDistance,
+		GeoSort:         query.GeoSort,
+	}
+
+	if query.DefaultOperator == V1OperatorAnd {
+		echoed.DefaultOperator = V1OperatorAnd
+	}
+
+	if query.SortMode == "asc" {
+		echoed.SortMode = "asc"
+	}
+
+	if query.MultiMatch != nil {
+		echoed.MultiMatch = &V1EchoedMultiMatch{
+			Pattern: v1RegString(query.MultiMatch.Pattern),
+			Fields:  query.MultiMatch.Fields,
+		}
+	}
+
+	return echoed
+}
+
+func v1RegStrings(regs map[string]*regexp.Regexp) map[string]string {
+	if regs == nil {
+		return nil
+	}
+
+	strs := make(map[string]string, len(regs))
+	for k, reg := range regs {
+		strs[k] = v1RegString(reg)
+	}
+
+	return strs
+}
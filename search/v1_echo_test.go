@@ -0,0 +1,52 @@
+package search
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1EchoQuery(t *testing.T) {
+	index := v1TestIndex(t, "echo-v2")
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{
+		Collation:   "ZH-HANS_CI",
+		Normalizers: map[string][]string{"tag": {V1NormalizerLowercase}},
+	}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"tag": "go", "name": "light"}}))
+
+	assert.Nil(t, V1PutAlias(nil, "echo", index))
+	t.Cleanup(func() { V1DeleteAlias(nil, "echo") })
+	assert.NotNil(t, V1PutAlias(nil, index, "echo"))
+
+	// No index is created under the alias, the writes would be lost to its queries
+	assert.NotNil(t, V1Put(nil, &V1Request{Index: "echo", ID: "2"}))
+	assert.NotNil(t, V1Transaction(nil, "echo", []V1Op{{Op: V1OpPut, Request: &V1Request{ID: "2"}}}))
+	assert.True(t, errors.Is(V1Delete(nil, "echo", "1"), ErrIndexNotFound))
+	assert.True(t, errors.Is(V1Reset(nil, "echo"), ErrIndexNotFound))
+	if peek, err := V1Peak(nil, index); assert.Nil(t, err) {
+		assert.Equal(t, 1, peek.Total)
+	}
+
+	response, _ := V1(nil, &V1Request{Index: "echo", EchoQuery: true, Query: &V1RequestQuery{
+		RegsAnd: map[string]*regexp.Regexp{"name": regexp.MustCompile("^li")},
+		Filters: map[string]string{"tag": "GO"},
+		SortBys: "name",
+	}})
+	assert.Equal(t, 1, response.Hits.Total)
+	assert.Equal(t, &V1EchoedQuery{
+		Index:           index,
+		RegsAnd:         map[string]string{"name": "^li"},
+		Filters:         map[string]string{"tag": "go"},
+		DefaultOperator: V1OperatorOr,
+		SortMode:        "desc",
+		SortBys:         "name",
+		Collation:       "ZH-HANS_CI",
+	}, response.Query)
+
+	// Not echoed unless asked
+	response, _ = V1(nil, &V1Request{Index: "echo", Query: &V1RequestQuery{}})
+	assert.Equal(t, 1, response.Hits.Total)
+	assert.Nil(t, response.Query)
+}
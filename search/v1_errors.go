@@ -0,0 +1,18 @@
+package search
+
+import "errors"
+
+// The errors of search v1, the returned errors wrap them with the details, so check them with errors.Is
+var (
+	ErrIndexNotFound = errors.New("index not found")
+	// ErrIndexExists is returned by V1Clone when the dest index is already there
+	ErrIndexExists = errors.New("index already exists")
+	// ErrCapacityExceeded is returned when there's no room for another index, or for another doc in a full index
+	ErrCapacityExceeded = errors.New("capacity exceeded")
+	ErrDocNotFound      = errors.New("doc not found")
+	ErrReadOnly         = errors.New("index is read-only")
+	// ErrVersionConflict is returned when a write conflicts with the current state of the doc
+	ErrVersionConflict = errors.New("version conflict")
+	// ErrNotModified is returned by V1Put for a doc left as it was, see V1IndexConfig.Dedupe
+	ErrNotModified = errors.New("not modified")
+)
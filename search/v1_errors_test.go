@@ -0,0 +1,68 @@
+package search
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1ErrIndexNotFound(t *testing.T) {
+	index := v1TestIndex(t, "errors-missing")
+
+	_, err := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{}})
+	assert.True(t, errors.Is(err, ErrIndexNotFound))
+	assert.True(t, errors.Is(V1Delete(nil, index, "1"), ErrIndexNotFound))
+	assert.True(t, errors.Is(V1Reset(nil, index), ErrIndexNotFound))
+	assert.True(t, errors.Is(V1Undelete(nil, index, "1"), ErrIndexNotFound))
+	_, err = V1Clone(nil, index, index+"-copy")
+	assert.True(t, errors.Is(err, ErrIndexNotFound))
+}
+
+func TestV1ErrCapacityExceeded(t *testing.T) {
+	index := v1TestIndex(t, "errors-capacity")
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{MaxDocs: 1}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1"}))
+
+	err := V1Put(nil, &V1Request{Index: index, ID: "2"})
+	assert.True(t, errors.Is(err, ErrCapacityExceeded))
+	assert.False(t, errors.Is(err, ErrVersionConflict))
+}
+
+func TestV1ErrDocNotFound(t *testing.T) {
+	index := v1TestIndex(t, "errors-doc")
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{SoftDeleteWindow: time.Hour}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1"}))
+
+	assert.True(t, errors.Is(V1Delete(nil, index, "2"), ErrDocNotFound))
+	assert.True(t, errors.Is(V1Undelete(nil, index, "2"), ErrDocNotFound))
+	assert.False(t, errors.Is(V1Delete(nil, index, "2"), ErrIndexNotFound))
+}
+
+func TestV1ErrVersionConflict(t *testing.T) {
+	index := v1TestIndex(t, "errors-conflict")
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{SoftDeleteWindow: time.Hour}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", OpType: V1OpTypeCreate}))
+
+	assert.True(t, errors.Is(V1Put(nil, &V1Request{Index: index, ID: "1", OpType: V1OpTypeCreate}), ErrVersionConflict))
+
+	// Undeleting over a doc put again conflicts too
+	assert.Nil(t, V1Delete(nil, index, "1"))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1"}))
+	assert.True(t, errors.Is(V1Undelete(nil, index, "1"), ErrVersionConflict))
+}
+
+func TestV1PeakErrIndexNotFound(t *testing.T) {
+	index := v1TestIndex(t, "errors-peak")
+
+	peek, err := V1Peak(nil, index)
+	assert.Nil(t, peek)
+	assert.True(t, errors.Is(err, ErrIndexNotFound))
+
+	// An empty index is a valid result
+	assert.Nil(t, V1Index(nil, index))
+	peek, err = V1Peak(nil, index)
+	assert.Nil(t, err)
+	assert.Equal(t, &V1PeekResult{Index: index, Initialized: true, Total: 0}, peek)
+}
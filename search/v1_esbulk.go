@@ -0,0 +1,209 @@
+package search
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The actions of the Elasticsearch bulk format, an index or create line is followed by the doc, a delete line isn't
+const (
+	V1ESActionIndex  = "index"
+	V1ESActionCreate = "create"
+	V1ESActionDelete = "delete"
+)
+
+// V1ESBulkAction is an action of an Elasticsearch bulk payload, Request carries the index, the id and,
+// unless it's a delete, the doc
+type V1ESBulkAction struct {
+	Action  string
+	Request *V1Request
+}
+
+// v1ESBulkMeta is the metadata of an action line
+type v1ESBulkMeta struct {
+	Index string `json:"_index,omitempty"`
+	ID    string `json:"_id,omitempty"`
+}
+
+// V1ParseESBulk reads the NDJSON bulk payload of Elasticsearch, actions without an _index go to index.
+// The top-level scalars of a doc become its keywords, numbers as they were written, and the whole doc its source
+func V1ParseESBulk(r io.Reader, index string) ([]*V1ESBulkAction, error) {
+	reader := bufio.NewReader(r)
+	actions := make([]*V1ESBulkAction, 0)
+
+	line := 0
+	next := func() ([]byte, error) {
+		for {
+			b, err := reader.ReadBytes('\n')
+			if len(b) > 0 || err == nil {
+				line++
+				if b = bytes.TrimSpace(b); len(b) > 0 {
+					return b, nil
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for {
+		b, err := next()
+		if errors.Is(err, io.EOF) {
+			return actions, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var metas map[string]*v1ESBulkMeta
+		if err := json.Unmarshal(b, &metas); err != nil || len(metas) != 1 {
+			return nil, fmt.Errorf("line %d: invalid bulk action %s", line, b)
+		}
+
+		action := &V1ESBulkAction{}
+		var meta *v1ESBulkMeta
+		for name, m := range metas {
+			action.Action, meta = name, m
+		}
+		if meta == nil {
+			meta = &v1ESBulkMeta{}
+		}
+		if meta.Index == "" {
+			meta.Index = index
+		}
+		action.Request = &V1Request{Index: meta.Index, ID: meta.ID}
+
+		switch action.Action {
+		case V1ESActionDelete:
+			if meta.ID == "" {
+				return nil, fmt.Errorf("line %d: delete without an _id", line)
+			}
+		case V1ESActionIndex, V1ESActionCreate:
+			if action.Action == V1ESActionCreate {
+				action.Request.OpType = V1OpTypeCreate
+			}
+
+			b, err := next()
+			if errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("line %d: %s without a doc", line, action.Action)
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			if action.Request.Source, action.Request.Keywords, err = v1ParseESDoc(b); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		default:
+			return nil, fmt.Errorf("line %d: unsupported bulk action %s", line, action.Action)
+		}
+
+		actions = append(actions, action)
+	}
+}
+
+func v1ParseESDoc(b []byte) (map[string]interface{}, map[string]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	var source map[string]interface{}
+	if err := decoder.Decode(&source); err != nil || source == nil {
+		return nil, nil, fmt.Errorf("invalid doc %s", b)
+	}
+
+	keywords := make(map[string]string, len(source))
+	for k, v := range source {
+		switch v := v.(type) {
+		case string:
+			keywords[k] = v
+		case json.Number:
+			keywords[k] = v.String()
+		case bool:
+			keywords[k] = strconv.FormatBool(v)
+		}
+	}
+
+	return source, keywords, nil
+}
+
+// V1ApplyESBulk runs the actions one by one like V1Bulk, a failed item doesn't stop the following ones
+func V1ApplyESBulk(ctx *gin.Context, actions []*V1ESBulkAction) *V1BulkResponse {
+	start := time.Now()
+
+	response := &V1BulkResponse{
+		Items: make([]*V1BulkItem, 0, len(actions)),
+	}
+
+	for _, action := range actions {
+		item := &V1BulkItem{Index: action.Request.Index}
+
+		if action.Action == V1ESActionDelete {
+			item.ID = action.Request.ID
+			response.add(item, V1ResultDeleted, V1Delete(ctx, action.Request.Index, action.Request.ID))
+			continue
+		}
+
+		result, err := v1Put(ctx, action.Request)
+		item.ID = action.Request.ID
+		response.add(item, result, err)
+	}
+
+	response.Took = time.Since(start).Milliseconds()
+
+	return response
+}
+
+// V1ImportESBulk parses the Elasticsearch bulk payload and applies it, see V1ParseESBulk
+func V1ImportESBulk(ctx *gin.Context, index string, r io.Reader) (*V1BulkResponse, error) {
+	actions, err := V1ParseESBulk(r, index)
+	if err != nil {
+		return nil, err
+	}
+
+	return V1ApplyESBulk(ctx, actions), nil
+}
+
+// V1WriteESBulk writes an index action for each doc in the Elasticsearch bulk format, the doc is its source
+func V1WriteESBulk(w io.Writer, docs []*V1Doc) error {
+	writer := bufio.NewWriter(w)
+	encoder := json.NewEncoder(writer)
+
+	for _, doc := range docs {
+		if err := encoder.Encode(map[string]*v1ESBulkMeta{V1ESActionIndex: {Index: doc.Index, ID: doc.ID}}); err != nil {
+			return err
+		}
+
+		if err := encoder.Encode(doc.Source); err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
+
+// V1ExportESBulk writes every doc of the index in the Elasticsearch bulk format, in the order they were written
+func V1ExportESBulk(ctx *gin.Context, index string, w io.Writer) error {
+	wrapper, unlock, err := v1LockIndex(index, false)
+	if err != nil {
+		return err
+	}
+
+	docs := wrapper.copies()
+	unlock()
+
+	sort.Slice(docs, func(i, j int) bool {
+		return docs[i].SeqNo < docs[j].SeqNo
+	})
+
+	return V1WriteESBulk(w, docs)
+}
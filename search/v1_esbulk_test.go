@@ -0,0 +1,73 @@
+package search
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1ESBulkRoundTrip(t *testing.T) {
+	index := v1TestIndex(t, "es-bulk")
+	copied := v1TestIndex(t, "es-bulk-copy")
+
+	payload := `{"index":{"_id":"1"}}
+{"title":"Hello","likes":42,"draft":false,"tags":["a","b"]}
+{"create":{"_index":"es-bulk","_id":"2"}}
+{"title":"World","likes":7.5}
+
+{"index":{"_id":"3"}}
+{"title":"Gone"}
+{"delete":{"_id":"3"}}
+{"create":{"_id":"1"}}
+{"title":"Conflict"}
+{"delete":{"_id":"4"}}
+`
+
+	response, err := V1ImportESBulk(nil, index, strings.NewReader(payload))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, response.Created)
+	assert.Equal(t, 1, response.Deleted)
+	assert.Equal(t, 2, response.Errors)
+	if assert.Len(t, response.Items, 6) {
+		assert.Equal(t, &V1BulkItem{Index: index, ID: "3", Result: V1ResultDeleted}, response.Items[3])
+		assert.Equal(t, V1ResultError, response.Items[4].Result)
+		assert.Equal(t, V1ResultError, response.Items[5].Result)
+	}
+
+	// Scalars are searchable keywords, the rest stays in the source
+	hits, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{Filters: map[string]string{"likes": ">10"}}})
+	if assert.Equal(t, 1, hits.Hits.Total) {
+		assert.Equal(t, "1", hits.Hits.Hits[0].ID)
+		assert.Equal(t, "false", hits.Hits.Hits[0].Source["draft"])
+		assert.Len(t, hits.Hits.Hits[0].Source["tags"], 2)
+	}
+
+	exported := &bytes.Buffer{}
+	assert.Nil(t, V1ExportESBulk(nil, index, exported))
+	assert.Equal(t, 4, strings.Count(exported.String(), "\n"))
+	assert.True(t, strings.HasPrefix(exported.String(), `{"index":{"_index":"es-bulk","_id":"1"}}`))
+
+	// The exported payload imports into another index as the same docs
+	actions, err := V1ParseESBulk(strings.NewReader(strings.ReplaceAll(exported.String(), `"_index":"es-bulk",`, "")), copied)
+	assert.Nil(t, err)
+	assert.Len(t, actions, 2)
+	response = V1ApplyESBulk(nil, actions)
+	assert.Equal(t, 2, response.Created)
+
+	for _, id := range []string{"1", "2"} {
+		original, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{IDs: []string{id}}})
+		clone, _ := V1(nil, &V1Request{Index: copied, Query: &V1RequestQuery{IDs: []string{id}}})
+		assert.Equal(t, original.Hits.Hits[0].Source, clone.Hits.Hits[0].Source)
+	}
+
+	_, err = V1ParseESBulk(strings.NewReader(`{"update":{"_id":"1"}}`+"\n"+`{"doc":{}}`), index)
+	assert.NotNil(t, err)
+	_, err = V1ParseESBulk(strings.NewReader(`{"index":{"_id":"1"}}`), index)
+	assert.NotNil(t, err)
+	_, err = V1ParseESBulk(strings.NewReader(`{"delete":{}}`), index)
+	assert.NotNil(t, err)
+	assert.True(t, errors.Is(V1ExportESBulk(nil, "es-bulk-missing", exported), ErrIndexNotFound))
+}
@@ -0,0 +1,69 @@
+package search
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	V1EventPut          = "put"
+	V1EventDelete       = "delete"
+	V1EventReset        = "reset"
+	V1EventIndexEvicted = "index_evicted"
+)
+
+// V1ChangeEvent tells a watcher about a change of the store
+type V1ChangeEvent struct {
+	Type  string `json:"type"`
+	Index string `json:"index"`
+	ID    string `json:"id,omitempty"`
+	At    int64  `json:"at"`
+}
+
+var (
+	v1WatchLock   = &sync.RWMutex{}
+	v1Watchers    = make(map[int]func(*V1ChangeEvent))
+	v1WatcherNext int
+)
+
+// V1Watch calls fn for every change until cancel is called, fn is called synchronously
+// outside of any index lock, so it may query the store but should return quickly
+func V1Watch(fn func(*V1ChangeEvent)) (cancel func()) {
+	v1WatchLock.Lock()
+	defer v1WatchLock.Unlock()
+
+	id := v1WatcherNext
+	v1WatcherNext++
+	v1Watchers[id] = fn
+
+	return func() {
+		v1WatchLock.Lock()
+		defer v1WatchLock.Unlock()
+
+		delete(v1Watchers, id)
+	}
+}
+
+// v1Emit notifies the watchers, it must not be called with an index lock held
+func v1Emit(events ...*V1ChangeEvent) {
+	v1WatchLock.RLock()
+	watchers := make([]func(*V1ChangeEvent), 0, len(v1Watchers))
+	for _, fn := range v1Watchers {
+		watchers = append(watchers, fn)
+	}
+	v1WatchLock.RUnlock()
+
+	for _, event := range events {
+		if event == nil {
+			continue
+		}
+
+		if event.At == 0 {
+			event.At = time.Now().Unix()
+		}
+
+		for _, fn := range watchers {
+			fn(event)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package search
+
+// v1Evictions are the docs that left an index, collected under the lock and handed to OnEvict after it's released
+type v1Evictions struct {
+	onEvict func(*V1Doc)
+	docs    []*V1Doc
+}
+
+// evictions collects the removed docs if the index has an OnEvict callback, the caller must hold the lock
+func (w *v1IndexWrapper) evictions(docs ...*V1Doc) *v1Evictions {
+	if w.Config.OnEvict == nil || len(docs) == 0 {
+		return nil
+	}
+
+	return &v1Evictions{onEvict: w.Config.OnEvict, docs: docs}
+}
+
+// all returns every doc of the index including the soft-deleted ones, the caller must hold the lock
+func (w *v1IndexWrapper) all() []*V1Doc {
+	docs := make([]*V1Doc, 0, len(w.Naive)+len(w.trash))
+	for _, doc := range w.Naive {
+		docs = append(docs, doc)
+	}
+	for _, trashed := range w.trash {
+		docs = append(docs, trashed.doc)
+	}
+
+	return docs
+}
+
+// notify calls OnEvict with a copy of each doc, it must not be called with an index lock held
+func (e *v1Evictions) notify() {
+	if e == nil {
+		return
+	}
+
+	for _, doc := range e.docs {
+		e.onEvict(v1CopyDoc(doc))
+	}
+}
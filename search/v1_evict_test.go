@@ -0,0 +1,85 @@
+package search
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1OnEvict(t *testing.T) {
+	var lock sync.Mutex
+	evicted := make([]string, 0)
+	onEvict := func(doc *V1Doc) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		evicted = append(evicted, doc.ID)
+	}
+	drain := func() []string {
+		lock.Lock()
+		defer lock.Unlock()
+
+		drained := evicted
+		evicted = make([]string, 0)
+		sort.Strings(drained)
+		return drained
+	}
+
+	index := v1TestIndex(t, "on-evict")
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{OnEvict: onEvict, MaxDocs: 3, MaxDocsPolicy: V1MaxDocsPolicyEvictOldest}))
+	for _, id := range []string{"1", "2", "3"} {
+		assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: id}))
+	}
+
+	// Updates aren't removals
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "3", Keywords: map[string]string{"v": "2"}}))
+	assert.Equal(t, []string{}, drain())
+
+	// MaxDocs eviction
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "4"}))
+	assert.Equal(t, []string{"1"}, drain())
+
+	// Delete
+	assert.Nil(t, V1Delete(nil, index, "2"))
+	assert.Equal(t, []string{"2"}, drain())
+
+	// Reset
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "5"}))
+	assert.Nil(t, V1Reset(nil, index))
+	assert.Equal(t, []string{"3", "4", "5"}, drain())
+
+	// Soft deletes leave once they expire
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{OnEvict: onEvict, SoftDeleteWindow: 10 * time.Millisecond}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "6"}))
+	assert.Nil(t, V1Delete(nil, index, "6"))
+	assert.Equal(t, []string{}, drain())
+	time.Sleep(20 * time.Millisecond)
+	_, err := V1Sweep(nil, index)
+	assert.Nil(t, err)
+	_, err = V1Sweep(nil, index)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"6"}, drain())
+
+	// Deleting an id again within the window evicts the doc trashed before
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{OnEvict: onEvict, SoftDeleteWindow: time.Hour}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "9"}))
+	assert.Nil(t, V1Delete(nil, index, "9"))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "9"}))
+	assert.Nil(t, V1Delete(nil, index, "9"))
+	assert.Equal(t, []string{"9"}, drain())
+	assert.Nil(t, V1Transaction(nil, index, []V1Op{
+		{Op: V1OpPut, Request: &V1Request{ID: "9"}},
+		{Op: V1OpDelete, Request: &V1Request{ID: "9"}},
+	}))
+	assert.Equal(t, []string{"9"}, drain())
+
+	// Dropping the index, along with the docs still in the trash
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "7"}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "8"}))
+	assert.Nil(t, V1Delete(nil, index, "8"))
+	assert.True(t, v1DropIndex(index))
+	assert.Equal(t, []string{"7", "8", "9"}, drain())
+}
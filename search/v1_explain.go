@@ -0,0 +1,90 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	V1ClauseRegsAnd     = "regs_and"
+	V1ClauseRegsOr      = "regs_or"
+	V1ClauseFilters     = "filters"
+	V1ClauseMultiMatch  = "multi_match"
+	V1ClauseTermsAll    = "terms_all"
+	V1ClauseTerms       = "terms"
+	V1ClauseSinceSeqNo  = "since_seq_no"
+	V1ClauseAnyField    = "any_field"
+	V1ClauseRanges      = "ranges"
+	V1ClauseIDs         = "ids"
+	V1ClauseGeoDistance = "geo_distance"
+)
+
+// V1Explanation tells why a single doc did or didn't match a query
+type V1Explanation struct {
+	Index   string                 `json:"index"`
+	ID      string                 `json:"id"`
+	Matched bool                   `json:"matched"`
+	Clauses []*V1ExplanationClause `json:"clauses"`
+}
+
+// V1ExplanationClause is the outcome of a single query clause against the doc
+type V1ExplanationClause struct {
+	Clause string `json:"clause"`
+	Field  string `json:"field"`
+	// Expected is the regex or the filter of the clause
+	Expected string `json:"expected"`
+	// Value is the stored keyword value, empty if the doc doesn't have the field
+	Value   string `json:"value"`
+	Found   bool   `json:"found"`
+	Matched bool   `json:"matched"`
+}
+
+func (e *V1Explanation) add(clause, field, expected, value string, found, matched bool) {
+	if e == nil {
+		return
+	}
+
+	e.Clauses = append(e.Clauses, &V1ExplanationClause{
+		Clause:   clause,
+		Field:    field,
+		Expected: expected,
+		Value:    value,
+		Found:    found,
+		Matched:  matched,
+	})
+}
+
+// V1ExplainDoc evaluates the query against a single doc and breaks the result down per clause
+func V1ExplainDoc(ctx *gin.Context, index, id string, query *V1RequestQuery) (*V1Explanation, error) {
+	w, unlock, err := v1LockIndex(index, false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	doc, found := w.Naive[id]
+	if !found {
+		return nil, fmt.Errorf("%w: %s in index %s", ErrDocNotFound, id, index)
+	}
+
+	explanation := &V1Explanation{
+		Index:   index,
+		ID:      id,
+		Clauses: make([]*V1ExplanationClause, 0),
+	}
+
+	query = v1NormalizeQuery(w.Config, v1ExpandSynonyms(w.synonyms, query))
+	explanation.Matched = v1Match(query, doc, explanation).Matched
+
+	sort.SliceStable(explanation.Clauses, func(i, j int) bool {
+		if explanation.Clauses[i].Clause != explanation.Clauses[j].Clause {
+			return explanation.Clauses[i].Clause < explanation.Clauses[j].Clause
+		}
+
+		return explanation.Clauses[i].Field < explanation.Clauses[j].Field
+	})
+
+	return explanation, nil
+}
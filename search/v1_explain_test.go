@@ -0,0 +1,41 @@
+package search
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1ExplainDoc(t *testing.T) {
+	index := v1TestIndex(t, "explain-doc")
+
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"title": "hello world", "status": "open"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2", Keywords: map[string]string{"title": "goodbye world", "status": "closed"}}))
+
+	query := &V1RequestQuery{
+		RegsAnd: map[string]*regexp.Regexp{"title": regexp.MustCompile("hello")},
+		Filters: map[string]string{"status": "open"},
+	}
+
+	explanation, err := V1ExplainDoc(nil, index, "1", query)
+	if assert.Nil(t, err) {
+		assert.True(t, explanation.Matched)
+		assert.Equal(t, []*V1ExplanationClause{
+			{Clause: V1ClauseFilters, Field: "status", Expected: "open", Value: "open", Found: true, Matched: true},
+			{Clause: V1ClauseRegsAnd, Field: "title", Expected: "hello", Value: "hello world", Found: true, Matched: true},
+		}, explanation.Clauses)
+	}
+
+	explanation, err = V1ExplainDoc(nil, index, "2", query)
+	if assert.Nil(t, err) {
+		assert.False(t, explanation.Matched)
+		assert.Equal(t, []*V1ExplanationClause{
+			{Clause: V1ClauseFilters, Field: "status", Expected: "open", Value: "closed", Found: true, Matched: false},
+			{Clause: V1ClauseRegsAnd, Field: "title", Expected: "hello", Value: "goodbye world", Found: true, Matched: false},
+		}, explanation.Clauses)
+	}
+
+	_, err = V1ExplainDoc(nil, index, "3", query)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,98 @@
+package search
+
+import "sort"
+
+const (
+	V1FacetOrderCount = "count"
+	V1FacetOrderKey   = "key"
+)
+
+// V1Facets asks for the value counts of keyword fields over the matched docs
+type V1Facets struct {
+	Fields []string `json:"fields"`
+	// Order is either "count" (desc, the default) or "key" (asc)
+	Order string `json:"order,omitempty"`
+	// Size keeps the top N buckets per field, 0 means all
+	Size int `json:"size,omitempty"`
+	// ExcludeOwnFilters counts the values of a field with a filter over the docs matching the query
+	// without that filter, so the other values of the field keep their counts while one is selected
+	ExcludeOwnFilters bool `json:"exclude_own_filters,omitempty"`
+}
+
+// V1FacetBucket is a single value count of a facet field
+type V1FacetBucket struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// v1TallyFacets counts the facet field values of the docs in a single scan
+func v1TallyFacets(recalls []*v1Recall, facets *V1Facets) map[string]map[string]int {
+	tally := make(map[string]map[string]int, len(facets.Fields))
+	for _, field := range facets.Fields {
+		tally[field] = make(map[string]int)
+	}
+
+	for _, recall := range recalls {
+		for field, counts := range tally {
+			if v, found := recall.Doc.Keywords[field]; found {
+				counts[v]++
+			}
+		}
+	}
+
+	return tally
+}
+
+// v1FacetQueries returns the query without its filter on the field for each facet field with a filter,
+// nil unless the facets exclude their own filters
+func v1FacetQueries(query *V1RequestQuery, facets *V1Facets) map[string]*V1RequestQuery {
+	if facets == nil || !facets.ExcludeOwnFilters {
+		return nil
+	}
+
+	queries := make(map[string]*V1RequestQuery)
+	for _, field := range facets.Fields {
+		if _, found := query.Filters[field]; !found {
+			continue
+		}
+
+		relaxed := *query
+		relaxed.Filters = make(map[string]string, len(query.Filters)-1)
+		for k, filter := range query.Filters {
+			if k != field {
+				relaxed.Filters[k] = filter
+			}
+		}
+		queries[field] = &relaxed
+	}
+
+	return queries
+}
+
+// v1FacetBuckets turns the tally into ordered and truncated bucket slices
+func v1FacetBuckets(tally map[string]map[string]int, facets *V1Facets) map[string][]*V1FacetBucket {
+	result := make(map[string][]*V1FacetBucket, len(tally))
+
+	for field, counts := range tally {
+		buckets := make([]*V1FacetBucket, 0, len(counts))
+		for k, c := range counts {
+			buckets = append(buckets, &V1FacetBucket{Key: k, Count: c})
+		}
+
+		sort.Slice(buckets, func(i, j int) bool {
+			if facets.Order != V1FacetOrderKey && buckets[i].Count != buckets[j].Count {
+				return buckets[i].Count > buckets[j].Count
+			}
+
+			return buckets[i].Key < buckets[j].Key
+		})
+
+		if facets.Size > 0 && len(buckets) > facets.Size {
+			buckets = buckets[:facets.Size]
+		}
+
+		result[field] = buckets
+	}
+
+	return result
+}
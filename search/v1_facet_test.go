@@ -0,0 +1,121 @@
+package search
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1FacetBuckets(t *testing.T) {
+	index := v1TestIndex(t, "facet-buckets")
+
+	colors := []string{"red", "red", "red", "blue", "blue", "green", "black", "black", "black", "black"}
+	for i, color := range colors {
+		assert.Nil(t, V1Put(nil, &V1Request{
+			Index:    index,
+			ID:       fmt.Sprint(i + 1),
+			Keywords: map[string]string{"color": color},
+		}))
+	}
+
+	response, _ := V1(nil, &V1Request{
+		Index: index,
+		Query: &V1RequestQuery{},
+		Facets: &V1Facets{
+			Fields: []string{"color"},
+			Size:   3,
+		},
+	})
+
+	assert.Equal(t, []*V1FacetBucket{
+		{Key: "black", Count: 4},
+		{Key: "red", Count: 3},
+		{Key: "blue", Count: 2},
+	}, response.Facets["color"])
+
+	response, _ = V1(nil, &V1Request{
+		Index: index,
+		Query: &V1RequestQuery{},
+		Facets: &V1Facets{
+			Fields: []string{"color"},
+			Order:  V1FacetOrderKey,
+			Size:   2,
+		},
+	})
+
+	assert.Equal(t, []*V1FacetBucket{
+		{Key: "black", Count: 4},
+		{Key: "blue", Count: 2},
+	}, response.Facets["color"])
+}
+
+func TestV1PostFilter(t *testing.T) {
+	index := v1TestIndex(t, "post-filter")
+
+	colors := []string{"red", "red", "blue", "green"}
+	for i, color := range colors {
+		assert.Nil(t, V1Put(nil, &V1Request{
+			Index:    index,
+			ID:       fmt.Sprint(i + 1),
+			Keywords: map[string]string{"color": color},
+		}))
+	}
+
+	response, _ := V1(nil, &V1Request{
+		Index:      index,
+		Query:      &V1RequestQuery{},
+		Facets:     &V1Facets{Fields: []string{"color"}},
+		PostFilter: &V1RequestQuery{Filters: map[string]string{"color": "red"}},
+	})
+
+	// Facet counts reflect the broad query
+	assert.Equal(t, []*V1FacetBucket{
+		{Key: "red", Count: 2},
+		{Key: "blue", Count: 1},
+		{Key: "green", Count: 1},
+	}, response.Facets["color"])
+
+	// Hits reflect the selection
+	if assert.Equal(t, 2, response.Hits.Total) {
+		for _, hit := range response.Hits.Hits {
+			assert.Equal(t, "red", hit.Source["color"])
+		}
+	}
+}
+
+func TestV1FacetsExcludeOwnFilters(t *testing.T) {
+	index := v1TestIndex(t, "facets-exclude-own-filters")
+
+	for i, doc := range [][]string{{"red", "S"}, {"red", "L"}, {"blue", "L"}, {"blue", "S"}, {"green", "L"}, {"red", "M"}} {
+		assert.Nil(t, V1Put(nil, &V1Request{
+			Index:    index,
+			ID:       fmt.Sprint(i + 1),
+			Keywords: map[string]string{"color": doc[0], "size": doc[1], "brand": "acme"},
+		}))
+	}
+
+	request := &V1Request{
+		Index: index,
+		Query: &V1RequestQuery{Filters: map[string]string{"color": "red", "size": "L"}},
+		Facets: &V1Facets{
+			Fields:            []string{"color", "size", "brand"},
+			Order:             V1FacetOrderKey,
+			ExcludeOwnFilters: true,
+		},
+	}
+
+	// Each selection narrows the other facet but not its own, unfiltered fields count the hits
+	response, err := V1(nil, request)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, response.Hits.Total)
+	assert.Equal(t, []*V1FacetBucket{{Key: "blue", Count: 1}, {Key: "green", Count: 1}, {Key: "red", Count: 1}}, response.Facets["color"])
+	assert.Equal(t, []*V1FacetBucket{{Key: "L", Count: 1}, {Key: "M", Count: 1}, {Key: "S", Count: 1}}, response.Facets["size"])
+	assert.Equal(t, []*V1FacetBucket{{Key: "acme", Count: 5}}, response.Facets["brand"])
+
+	// Otherwise the facets count the hits
+	request.Facets.ExcludeOwnFilters = false
+	response, _ = V1(nil, request)
+	assert.Equal(t, []*V1FacetBucket{{Key: "blue", Count: 1}, {Key: "green", Count: 1}, {Key: "red", Count: 3}}, response.Facets["color"])
+	assert.Equal(t, []*V1FacetBucket{{Key: "L", Count: 3}, {Key: "M", Count: 1}, {Key: "S", Count: 1}}, response.Facets["size"])
+}
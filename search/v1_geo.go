@@ -0,0 +1,97 @@
+package search
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// v1EarthRadiusKm is the mean radius of the earth the haversine distances are computed with
+const v1EarthRadiusKm = 6371.0088
+
+// V1GeoPoint is a location, docs keep theirs in a keyword field as "lat,lon"
+type V1GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// V1GeoDistance matches the docs whose location in Field is within DistanceKm of Point
+type V1GeoDistance struct {
+	Field      string     `json:"field"`
+	Point      V1GeoPoint `json:"point"`
+	DistanceKm float64    `json:"distance_km"`
+}
+
+// V1GeoSort orders the docs by the distance of their location in Field from Point, "asc" (the default)
+// or "desc", docs without a location come last
+type V1GeoSort struct {
+	Field string     `json:"field"`
+	Point V1GeoPoint `json:"point"`
+	Order string     `json:"order,omitempty"`
+}
+
+func (p V1GeoPoint) String() string {
+	return strconv.FormatFloat(p.Lat, 'g', -1, 64) + "," + strconv.FormatFloat(p.Lon, 'g', -1, 64)
+}
+
+// v1ParseGeoPoint parses a "lat,lon" value
+func v1ParseGeoPoint(v string) (V1GeoPoint, error) {
+	lat, lon, found := strings.Cut(v, ",")
+	if !found {
+		return V1GeoPoint{}, fmt.Errorf("invalid geo point %q", v)
+	}
+
+	point := V1GeoPoint{}
+	var err error
+	if point.Lat, err = strconv.ParseFloat(strings.TrimSpace(lat), 64); err != nil || point.Lat < -90 || point.Lat > 90 {
+		return V1GeoPoint{}, fmt.Errorf("invalid geo point %q", v)
+	}
+	if point.Lon, err = strconv.ParseFloat(strings.TrimSpace(lon), 64); err != nil || point.Lon < -180 || point.Lon > 180 {
+		return V1GeoPoint{}, fmt.Errorf("invalid geo point %q", v)
+	}
+
+	return point, nil
+}
+
+// v1Haversine is the great-circle distance between the points in km
+func v1Haversine(a, b V1GeoPoint) float64 {
+	const rad = math.Pi / 180
+
+	dLat := (b.Lat - a.Lat) * rad
+	dLon := (b.Lon - a.Lon) * rad
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(a.Lat*rad)*math.Cos(b.Lat*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * v1EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// v1GeoDistanceOf is the distance of the doc's location in the field from the point,
+// +Inf if the doc has no valid location
+func v1GeoDistanceOf(doc *V1Doc, field string, point V1GeoPoint) float64 {
+	v, found := doc.Keywords[field]
+	if !found {
+		return math.Inf(1)
+	}
+
+	location, err := v1ParseGeoPoint(v)
+	if err != nil {
+		return math.Inf(1)
+	}
+
+	return v1Haversine(point, location)
+}
+
+// v1QueryGeo returns the point the distances of the query are measured from, if any
+func v1QueryGeo(query *V1RequestQuery) *V1GeoSort {
+	if query.GeoSort != nil {
+		return query.GeoSort
+	}
+
+	if query.GeoDistance != nil {
+		return &V1GeoSort{Field: query.GeoDistance.Field, Point: query.GeoDistance.Point}
+	}
+
+	return nil
+}
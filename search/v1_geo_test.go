@@ -0,0 +1,89 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1GeoSort(t *testing.T) {
+	index := v1TestIndex(t, "geo")
+	for id, location := range map[string]string{
+		"eiffel":     "48.8584,2.2945",
+		"london":     "51.5074,-0.1278",
+		"louvre":     "48.8606, 2.3376",
+		"versailles": "48.8049,2.1204",
+		"nowhere":    "somewhere",
+	} {
+		assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: id, Keywords: map[string]string{"location": location}}))
+	}
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "unknown"}))
+
+	paris := V1GeoPoint{Lat: 48.8566, Lon: 2.3522}
+	search := func(query *V1RequestQuery) ([]string, []float64) {
+		response, _ := V1(nil, &V1Request{Index: index, Query: query})
+		ids, distances := make([]string, 0), make([]float64, 0)
+		for _, hit := range response.Hits.Hits {
+			ids = append(ids, hit.ID)
+			if hit.Distance != nil {
+				distances = append(distances, *hit.Distance)
+			}
+		}
+		return ids, distances
+	}
+
+	// Within 5km, nearest first
+	ids, distances := search(&V1RequestQuery{
+		GeoDistance: &V1GeoDistance{Field: "location", Point: paris, DistanceKm: 5},
+		GeoSort:     &V1GeoSort{Field: "location", Point: paris},
+	})
+	assert.Equal(t, []string{"louvre", "eiffel"}, ids)
+	if assert.Len(t, distances, 2) {
+		assert.InDelta(t, 1.15, distances[0], 0.05)
+		assert.InDelta(t, 4.2, distances[1], 0.1)
+	}
+
+	// Docs without a location come last either way
+	ids, distances = search(&V1RequestQuery{GeoSort: &V1GeoSort{Field: "location", Point: paris}})
+	assert.Equal(t, []string{"louvre", "eiffel", "versailles", "london"}, ids[:4])
+	assert.ElementsMatch(t, []string{"nowhere", "unknown"}, ids[4:])
+	assert.Len(t, distances, 4)
+	assert.InDelta(t, 343.5, distances[3], 1)
+	assert.IsIncreasing(t, distances)
+
+	ids, _ = search(&V1RequestQuery{GeoSort: &V1GeoSort{Field: "location", Point: paris, Order: "desc"}})
+	assert.Equal(t, []string{"london", "versailles", "eiffel", "louvre"}, ids[:4])
+
+	// The filter alone returns the distances too
+	ids, distances = search(&V1RequestQuery{GeoDistance: &V1GeoDistance{Field: "location", Point: paris, DistanceKm: 20}})
+	assert.ElementsMatch(t, []string{"louvre", "eiffel", "versailles"}, ids)
+	assert.Len(t, distances, 3)
+
+	explanation, err := V1ExplainDoc(nil, index, "london", &V1RequestQuery{GeoDistance: &V1GeoDistance{Field: "location", Point: paris, DistanceKm: 5}})
+	assert.Nil(t, err)
+	assert.False(t, explanation.Matched)
+	assert.Equal(t, V1ClauseGeoDistance, explanation.Clauses[0].Clause)
+}
+
+func TestV1GeoPageToken(t *testing.T) {
+	index := v1TestIndex(t, "geo-page")
+	for i, location := range []string{"0,0", "0,1", "0,2", "0,3", "", "0,4", ""} {
+		assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: string(rune('a' + i)), Keywords: map[string]string{"location": location}}))
+	}
+
+	query := &V1RequestQuery{GeoSort: &V1GeoSort{Field: "location", Point: V1GeoPoint{}}}
+	ids, token := make([]string, 0), ""
+	for {
+		// A page per hit, so the docs without a location are paged over too
+		response, _ := V1(nil, &V1Request{Index: index, Query: query, Size: 1, PageToken: token})
+		for _, hit := range response.Hits.Hits {
+			ids = append(ids, hit.ID)
+		}
+		if token = response.NextPageToken; token == "" {
+			break
+		}
+	}
+
+	assert.Equal(t, []string{"a", "b", "c", "d", "f"}, ids[:5])
+	assert.ElementsMatch(t, []string{"e", "g"}, ids[5:])
+}
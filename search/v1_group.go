@@ -0,0 +1,78 @@
+package search
+
+import "sort"
+
+const (
+	V1GroupOrderSize  = "size"
+	V1GroupOrderScore = "score"
+)
+
+// V1GroupBy partitions the ranked hits by the value of Field, docs without the field are left out
+type V1GroupBy struct {
+	Field string `json:"field"`
+	// TopN keeps the best N hits per group, 0 means 3
+	TopN int `json:"top_n,omitempty"`
+	// Order sorts the groups by "size" (the default) or by the "score" of their top hit
+	Order string `json:"order,omitempty"`
+}
+
+// V1ResponseGroup is the top hits of a group in rank order
+type V1ResponseGroup struct {
+	Key   string           `json:"key"`
+	Total int              `json:"total"`
+	Hits  []*V1ResponseHit `json:"hits"`
+}
+
+// v1Group partitions the sorted recalls, ties between groups are broken by the rank of their top hit
+func v1Group(recalls []*v1Recall, groupBy *V1GroupBy, hit func(*v1Recall) *V1ResponseHit) []*V1ResponseGroup {
+	topN := groupBy.TopN
+	if topN <= 0 {
+		topN = 3
+	}
+
+	groups := make([]*V1ResponseGroup, 0)
+	indexes := make(map[string]int)
+	tops := make([]*v1Recall, 0)
+
+	for _, recall := range recalls {
+		key, found := recall.Doc.Keywords[groupBy.Field]
+		if !found {
+			continue
+		}
+
+		i, exists := indexes[key]
+		if !exists {
+			i = len(groups)
+			indexes[key] = i
+			groups = append(groups, &V1ResponseGroup{Key: key, Hits: make([]*V1ResponseHit, 0, topN)})
+			tops = append(tops, recall)
+		}
+
+		group := groups[i]
+		group.Total++
+		if len(group.Hits) < topN {
+			group.Hits = append(group.Hits, hit(recall))
+		}
+	}
+
+	// Groups are created in the rank order of their top hit, a stable sort keeps it for ties
+	order := make([]int, len(groups))
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		if groupBy.Order == V1GroupOrderScore {
+			return tops[order[i]].Score > tops[order[j]].Score
+		}
+
+		return groups[order[i]].Total > groups[order[j]].Total
+	})
+
+	sorted := make([]*V1ResponseGroup, 0, len(groups))
+	for _, i := range order {
+		sorted = append(sorted, groups[i])
+	}
+
+	return sorted
+}
@@ -0,0 +1,57 @@
+package search
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1GroupBy(t *testing.T) {
+	index := v1TestIndex(t, "group-by")
+
+	categories := []string{"book", "book", "book", "book", "toy", "toy", "food"}
+	for i, category := range categories {
+		assert.Nil(t, V1Put(nil, &V1Request{
+			Index:    index,
+			ID:       fmt.Sprint(i + 1),
+			Keywords: map[string]string{"category": category},
+		}))
+	}
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "100"}))
+
+	response, _ := V1(nil, &V1Request{
+		Index:   index,
+		Query:   &V1RequestQuery{},
+		GroupBy: &V1GroupBy{Field: "category", TopN: 2},
+	})
+
+	if assert.Len(t, response.Groups, 3) {
+		keys := make([]string, 0)
+		for _, group := range response.Groups {
+			keys = append(keys, group.Key)
+			assert.LessOrEqual(t, len(group.Hits), 2)
+		}
+		assert.Equal(t, []string{"book", "toy", "food"}, keys)
+
+		// Ranked by SortableID desc within the group
+		book := response.Groups[0]
+		assert.Equal(t, 4, book.Total)
+		assert.Equal(t, "4", book.Hits[0].ID)
+		assert.Equal(t, "3", book.Hits[1].ID)
+	}
+
+	// By the rank of the top hit when scores tie
+	response, _ = V1(nil, &V1Request{
+		Index:   index,
+		Query:   &V1RequestQuery{},
+		GroupBy: &V1GroupBy{Field: "category", TopN: 1, Order: V1GroupOrderScore},
+	})
+
+	if assert.Len(t, response.Groups, 3) {
+		assert.Equal(t, "food", response.Groups[0].Key)
+		assert.Equal(t, "toy", response.Groups[1].Key)
+		assert.Equal(t, "book", response.Groups[2].Key)
+		assert.Len(t, response.Groups[2].Hits, 1)
+	}
+}
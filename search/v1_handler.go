@@ -0,0 +1,176 @@
+package search
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	V1FormatJSON = "json"
+	V1FormatCSV  = "csv"
+	V1FormatTSV  = "tsv"
+
+	v1MIMECSV = "text/csv"
+	v1MIMETSV = "text/tab-separated-values"
+)
+
+// V1RegisterRoutes registers the handlers of search v1 on the router
+func V1RegisterRoutes(r gin.IRouter) {
+	r.POST("/v1/:index/_search", V1SearchHandler)
+}
+
+// V1SearchHandler runs the V1Request in the body against the index of the path, the hits are returned
+// as JSON by default, or as CSV/TSV if asked by ?format= or the Accept header, with a column per ?fields=
+// source field, dotted fields reach into nested objects
+func V1SearchHandler(c *gin.Context) {
+	request := &V1Request{}
+	if err := c.ShouldBindJSON(request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	request.Index = c.Param("index")
+	if request.Query == nil {
+		request.Query = &V1RequestQuery{}
+	}
+
+	response, err := V1(c, request)
+	if errors.Is(err, ErrIndexNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	format := v1ResponseFormat(c)
+	if format == V1FormatJSON {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	var fields []string
+	if raw := c.Query("fields"); raw != "" {
+		fields = strings.Split(raw, ",")
+	}
+
+	contentType, comma := v1MIMECSV, ','
+	if format == V1FormatTSV {
+		contentType, comma = v1MIMETSV, '\t'
+	}
+
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", contentType+"; charset=utf-8")
+
+	if err := v1WriteDelimited(c.Writer, comma, response.Hits.Hits, fields); err != nil {
+		c.Error(err)
+	}
+}
+
+// v1ResponseFormat picks the format from ?format=, then from the Accept header
+func v1ResponseFormat(c *gin.Context) string {
+	switch format := strings.ToLower(c.Query("format")); format {
+	case V1FormatJSON, V1FormatCSV, V1FormatTSV:
+		return format
+	}
+
+	switch c.NegotiateFormat(gin.MIMEJSON, v1MIMECSV, v1MIMETSV) {
+	case v1MIMECSV:
+		return V1FormatCSV
+	case v1MIMETSV:
+		return V1FormatTSV
+	}
+
+	return V1FormatJSON
+}
+
+// v1WriteDelimited writes a header row of "_id" and the fields, then a row per hit,
+// all top-level source fields are written if none are given
+func v1WriteDelimited(w http.ResponseWriter, comma rune, hits []*V1ResponseHit, fields []string) error {
+	if len(fields) == 0 {
+		seen := make(map[string]bool)
+		for _, hit := range hits {
+			for k := range hit.Source {
+				if !seen[k] {
+					seen[k] = true
+					fields = append(fields, k)
+				}
+			}
+		}
+		sort.Strings(fields)
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = comma
+
+	if err := writer.Write(append([]string{"_id"}, fields...)); err != nil {
+		return err
+	}
+
+	for _, hit := range hits {
+		row := make([]string, 0, len(fields)+1)
+		row = append(row, hit.ID)
+		for _, field := range fields {
+			row = append(row, v1FormatCell(v1SourceValue(hit.Source, field)))
+		}
+
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// v1SourceValue looks up a dotted field in the source, nil if any part of the path is missing
+func v1SourceValue(source map[string]interface{}, field string) interface{} {
+	if v, found := source[field]; found {
+		return v
+	}
+
+	var current interface{} = source
+	for _, part := range strings.Split(field, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		if current, ok = object[part]; !ok {
+			return nil
+		}
+	}
+
+	return current
+}
+
+// v1FormatCell renders a scalar as is and anything nested as JSON, nil is an empty cell
+func v1FormatCell(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case bool, int, int64, json.Number:
+		return fmt.Sprint(value)
+	}
+
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(encoded)
+}
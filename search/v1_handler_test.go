@@ -0,0 +1,55 @@
+package search
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1SearchHandlerCSV(t *testing.T) {
+	index := v1TestIndex(t, "handler-csv")
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"name": "alpha"},
+		Source: map[string]interface{}{"meta": map[string]interface{}{"author": "ann"}, "price": 9.5}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2", Keywords: map[string]string{"name": "beta, gamma"},
+		Source: map[string]interface{}{"tags": []interface{}{"a", "b"}}}))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	V1RegisterRoutes(router)
+
+	search := func(target, accept string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(`{"query":{"sort_bys":"name","sort_mode":"asc"}}`))
+		if accept != "" {
+			r.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	// Missing and nested fields are handled, quoting follows CSV rules
+	w := search("/v1/"+index+"/_search?format=csv&fields=name,meta.author,price,tags", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "_id,name,meta.author,price,tags\n"+
+		"1,alpha,ann,9.5,\n"+
+		"2,\"beta, gamma\",,,\"[\"\"a\"\",\"\"b\"\"]\"\n", w.Body.String())
+
+	// Negotiated by the Accept header, every top-level field by default
+	w = search("/v1/"+index+"/_search", "text/tab-separated-values")
+	assert.Equal(t, http.StatusOK, w.Code)
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	assert.Equal(t, "_id\tmeta\tname\tprice\ttags", lines[0])
+	assert.Equal(t, "1\t\"{\"\"author\"\":\"\"ann\"\"}\"\talpha\t9.5\t", lines[1])
+	assert.Len(t, lines, 3)
+
+	// JSON stays the default
+	w = search("/v1/"+index+"/_search", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+	assert.Contains(t, w.Body.String(), `"total":2`)
+}
@@ -0,0 +1,296 @@
+package search
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	v1HighlightPreTag  = "<em>"
+	v1HighlightPostTag = "</em>"
+)
+
+// The encoders of the snippets, html escapes the text around and inside the tags but not the tags
+const (
+	V1HighlightEncoderDefault = "default"
+	V1HighlightEncoderHTML    = "html"
+)
+
+// V1HighlightOptions sets how the snippets are built, empty tags default to "<em>" and "</em>"
+type V1HighlightOptions struct {
+	PreTag  string `json:"pre_tag,omitempty"`
+	PostTag string `json:"post_tag,omitempty"`
+	// Fields overrides the tags per field, the fields not listed use the tags above
+	Fields map[string]*V1HighlightTags `json:"fields,omitempty"`
+	// Encoder is "default" (or empty) to keep the text as it is, or "html"
+	Encoder string `json:"encoder,omitempty"`
+}
+
+// V1HighlightTags are the tags a field's matches are wrapped in, an empty one falls back to the global tag
+type V1HighlightTags struct {
+	PreTag  string `json:"pre_tag,omitempty"`
+	PostTag string `json:"post_tag,omitempty"`
+}
+
+func (o *V1HighlightOptions) validate() error {
+	if o == nil {
+		return nil
+	}
+
+	switch o.Encoder {
+	case "", V1HighlightEncoderDefault, V1HighlightEncoderHTML:
+		return nil
+	default:
+		return fmt.Errorf("unknown highlight encoder %s", o.Encoder)
+	}
+}
+
+// tags returns the tags of the field
+func (o *V1HighlightOptions) tags(field string) (string, string) {
+	pre, post := v1HighlightPreTag, v1HighlightPostTag
+	if o == nil {
+		return pre, post
+	}
+
+	if o.PreTag != "" {
+		pre = o.PreTag
+	}
+	if o.PostTag != "" {
+		post = o.PostTag
+	}
+
+	if tags := o.Fields[field]; tags != nil {
+		if tags.PreTag != "" {
+			pre = tags.PreTag
+		}
+		if tags.PostTag != "" {
+			post = tags.PostTag
+		}
+	}
+
+	return pre, post
+}
+
+// encode encodes a piece of the text of a snippet
+func (o *V1HighlightOptions) encode(text string) string {
+	if o != nil && o.Encoder == V1HighlightEncoderHTML {
+		return html.EscapeString(text)
+	}
+
+	return text
+}
+
+// v1Highlighter computes the highlights of the hits of a query
+type v1Highlighter struct {
+	options *V1HighlightOptions
+	regs    map[string][]*regexp.Regexp
+	// folded are the case-insensitive variants of the regexes, for matching the original pre-normalization values
+	folded map[*regexp.Regexp]*regexp.Regexp
+}
+
+// newV1Highlighter collects the regexes of the query per field, limited to fields if it's not empty
+func newV1Highlighter(query *V1RequestQuery, fields []string, options *V1HighlightOptions) *v1Highlighter {
+	regs := make(map[string][]*regexp.Regexp)
+
+	add := func(field string, reg *regexp.Regexp) {
+		if reg != nil {
+			regs[field] = append(regs[field], reg)
+		}
+	}
+
+	for k, reg := range query.RegsAnd {
+		add(k, reg)
+	}
+
+	for k, reg := range query.RegsOr {
+		add(k, reg)
+	}
+
+	if query.MultiMatch != nil {
+		for _, k := range query.MultiMatch.Fields {
+			add(k, query.MultiMatch.Pattern)
+		}
+	}
+
+	if len(fields) > 0 {
+		requested := make(map[string]bool, len(fields))
+		for _, field := range fields {
+			requested[field] = true
+		}
+
+		for field := range regs {
+			if !requested[field] {
+				delete(regs, field)
+			}
+		}
+	}
+
+	return &v1Highlighter{
+		options: options,
+		regs:    regs,
+		folded:  make(map[*regexp.Regexp]*regexp.Regexp),
+	}
+}
+
+// fold returns the case-insensitive variant of the regex
+func (h *v1Highlighter) fold(reg *regexp.Regexp) *regexp.Regexp {
+	if folded, found := h.folded[reg]; found {
+		return folded
+	}
+
+	folded, err := regexp.Compile("(?i)" + reg.String())
+	if err != nil {
+		folded = reg
+	}
+	h.folded[reg] = folded
+
+	return folded
+}
+
+// highlight computes the highlights of the doc, ordered by field. Normalization may have lowercased
+// the stored value, so the original value is highlighted with the case-insensitive regexes instead.
+// If it has folded it too, the regexes match the folded original and the offsets are mapped back
+func (h *v1Highlighter) highlight(doc *V1Doc) []*V1ResponseHighlight {
+	highlights := make([]*V1ResponseHighlight, 0)
+
+	for field, fieldRegs := range h.regs {
+		v, found := doc.Keywords[field]
+		if !found {
+			continue
+		}
+
+		raw, hasRaw := doc.RawKeywords[field]
+		var folded *v1FoldedText
+		if hasRaw {
+			// A folded value stays the same folded again
+			if v1Fold(v) == v {
+				folded = v1FoldText(raw)
+			}
+			v = raw
+		}
+
+		text := v
+		if folded != nil {
+			text = folded.text
+		}
+
+		ranges := make([][]int, 0)
+		for _, reg := range fieldRegs {
+			if hasRaw {
+				reg = h.fold(reg)
+			}
+
+			for _, loc := range reg.FindAllStringIndex(text, -1) {
+				if loc[1] > loc[0] {
+					if folded != nil {
+						loc = folded.original(loc)
+					}
+					ranges = append(ranges, loc)
+				}
+			}
+		}
+
+		if len(ranges) == 0 {
+			continue
+		}
+
+		ranges = v1MergeRanges(ranges)
+
+		highlight := &V1ResponseHighlight{
+			Field:   field,
+			Offsets: make([]string, 0, len(ranges)),
+		}
+
+		pre, post := h.options.tags(field)
+		snippet := &strings.Builder{}
+		last := 0
+		for _, r := range ranges {
+			highlight.Offsets = append(highlight.Offsets, fmt.Sprintf("%d-%d", r[0], r[1]))
+
+			snippet.WriteString(h.options.encode(v[last:r[0]]))
+			snippet.WriteString(pre)
+			snippet.WriteString(h.options.encode(v[r[0]:r[1]]))
+			snippet.WriteString(post)
+			last = r[1]
+		}
+		snippet.WriteString(h.options.encode(v[last:]))
+		highlight.Snippet = snippet.String()
+
+		highlights = append(highlights, highlight)
+	}
+
+	sort.Slice(highlights, func(i, j int) bool {
+		return highlights[i].Field < highlights[j].Field
+	})
+
+	return highlights
+}
+
+// v1FoldedText is a text folded rune by rune, along with the [start, end) of the rune of the original each
+// of its bytes was folded from. The runes folded away, like combining accents, count to the rune before them
+type v1FoldedText struct {
+	text   string
+	starts []int
+	ends   []int
+}
+
+func v1FoldText(raw string) *v1FoldedText {
+	f := &v1FoldedText{}
+	text := &strings.Builder{}
+
+	start := 0
+	for i := 0; i < len(raw); {
+		_, size := utf8.DecodeRuneInString(raw[i:])
+		folded := v1Fold(raw[i : i+size])
+
+		switch {
+		case folded != "":
+			text.WriteString(folded)
+			for j := 0; j < len(folded); j++ {
+				f.starts = append(f.starts, start)
+				f.ends = append(f.ends, i+size)
+			}
+			start = i + size
+		case len(f.ends) > 0:
+			for j := len(f.ends) - 1; j >= 0 && f.ends[j] == i; j-- {
+				f.ends[j] = i + size
+			}
+			start = i + size
+		}
+
+		i += size
+	}
+	f.text = text.String()
+
+	return f
+}
+
+// original maps the non-empty [start, end) range of the folded text to the runes of the original it was folded from
+func (f *v1FoldedText) original(loc []int) []int {
+	return []int{f.starts[loc[0]], f.ends[loc[1]-1]}
+}
+
+// v1MergeRanges sorts the [start, end) ranges and merges the overlapping ones
+func v1MergeRanges(ranges [][]int) [][]int {
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i][0] < ranges[j][0]
+	})
+
+	merged := [][]int{ranges[0]}
+	for _, r := range ranges[1:] {
+		last := merged[len(merged)-1]
+		if r[0] <= last[1] {
+			if r[1] > last[1] {
+				merged[len(merged)-1] = []int{last[0], r[1]}
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	return merged
+}
@@ -0,0 +1,169 @@
+package search
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1HighlightFields(t *testing.T) {
+	index := v1TestIndex(t, "highlight-fields")
+
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"title": "go search", "body": "search in go, go fast"}}))
+
+	query := &V1RequestQuery{
+		MultiMatch: &V1MultiMatch{Pattern: regexp.MustCompile("go"), Fields: []string{"title", "body"}},
+	}
+
+	// All matched fields by default
+	response, _ := V1(nil, &V1Request{Index: index, Query: query, Highlight: true})
+	if assert.Equal(t, 1, response.Hits.Total) {
+		assert.Len(t, response.Hits.Hits[0].Highlights, 2)
+	}
+
+	response, _ = V1(nil, &V1Request{Index: index, Query: query, Highlight: true, HighlightFields: []string{"body"}})
+	if assert.Equal(t, 1, response.Hits.Total) {
+		assert.Equal(t, []*V1ResponseHighlight{
+			{
+				Field:   "body",
+				Offsets: []string{"10-12", "14-16"},
+				Snippet: "search in <em>go</em>, <em>go</em> fast",
+			},
+		}, response.Hits.Hits[0].Highlights)
+	}
+
+	// Nothing without the toggle
+	response, _ = V1(nil, &V1Request{Index: index, Query: query, HighlightFields: []string{"body"}})
+	if assert.Equal(t, 1, response.Hits.Total) {
+		assert.Empty(t, response.Hits.Hits[0].Highlights)
+	}
+}
+
+func TestV1HighlightOriginalText(t *testing.T) {
+	index := v1TestIndex(t, "highlight-original-text")
+
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{
+		Normalizers: map[string][]string{"title": {V1NormalizerLowercase}},
+	}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"title": "Hello, World!"}}))
+
+	response, _ := V1(nil, &V1Request{
+		Index:     index,
+		Query:     &V1RequestQuery{RegsAnd: map[string]*regexp.Regexp{"title": regexp.MustCompile("hello")}},
+		Highlight: true,
+	})
+
+	if assert.Equal(t, 1, response.Hits.Total) {
+		assert.Equal(t, []*V1ResponseHighlight{
+			{
+				Field:   "title",
+				Offsets: []string{"0-5"},
+				Snippet: "<em>Hello</em>, World!",
+			},
+		}, response.Hits.Hits[0].Highlights)
+	}
+}
+
+func TestV1HighlightFolding(t *testing.T) {
+	index := v1TestIndex(t, "highlight-folding")
+
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{Folding: true}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"title": "Café Crème"}}))
+	// Decomposed, the accent is a rune of its own
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2", Keywords: map[string]string{"title": "Cafe\u0301"}}))
+
+	response, _ := V1(nil, &V1Request{
+		Index:     index,
+		Query:     &V1RequestQuery{RegsOr: map[string]*regexp.Regexp{"title": regexp.MustCompile("cafe|creme")}, SortBys: "_id", SortMode: "asc"},
+		Highlight: true,
+	})
+
+	if assert.Equal(t, 2, response.Hits.Total) {
+		assert.Equal(t, []*V1ResponseHighlight{
+			{
+				Field:   "title",
+				Offsets: []string{"0-5", "6-12"},
+				Snippet: "<em>Café</em> <em>Crème</em>",
+			},
+		}, response.Hits.Hits[0].Highlights)
+		assert.Equal(t, []*V1ResponseHighlight{
+			{
+				Field:   "title",
+				Offsets: []string{"0-6"},
+				Snippet: "<em>Cafe\u0301</em>",
+			},
+		}, response.Hits.Hits[1].Highlights)
+	}
+}
+
+func TestV1HighlightOptions(t *testing.T) {
+	index := v1TestIndex(t, "highlight-options")
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{
+		"title": `<script>alert("go")</script>`,
+		"body":  "go & <b>go</b>",
+	}}))
+
+	query := &V1RequestQuery{
+		MultiMatch: &V1MultiMatch{Pattern: regexp.MustCompile("go|<b>"), Fields: []string{"title", "body"}},
+	}
+	snippets := func(options *V1HighlightOptions) map[string]string {
+		response, err := V1(nil, &V1Request{Index: index, Query: query, Highlight: true, HighlightOptions: options})
+		assert.Nil(t, err)
+		snippets := make(map[string]string)
+		for _, highlight := range response.Hits.Hits[0].Highlights {
+			snippets[highlight.Field] = highlight.Snippet
+		}
+		return snippets
+	}
+
+	// The text is escaped, the tags and what they wrap stay marked
+	assert.Equal(t, map[string]string{
+		"title": `&lt;script&gt;alert(&#34;<mark>go</mark>&#34;)&lt;/script&gt;`,
+		"body":  `<em>go</em> &amp; <em>&lt;b&gt;go</em>&lt;/b&gt;`,
+	}, snippets(&V1HighlightOptions{
+		Encoder: V1HighlightEncoderHTML,
+		Fields:  map[string]*V1HighlightTags{"title": {PreTag: "<mark>", PostTag: "</mark>"}},
+	}))
+
+	// Global tags apply to the fields not listed, a field may override only one of them
+	assert.Equal(t, map[string]string{
+		"title": `<script>alert("[go|")</script>`,
+		"body":  `[go| & [<b>go|</b>`,
+	}, snippets(&V1HighlightOptions{
+		PreTag:  "[",
+		PostTag: "]",
+		Fields:  map[string]*V1HighlightTags{"title": {PostTag: "|"}, "body": {PostTag: "|"}},
+	}))
+
+	// Unencoded by default
+	assert.Equal(t, `<em>go</em> & <em><b>go</em></b>`, snippets(nil)["body"])
+
+	_, err := V1(nil, &V1Request{Index: index, Query: query, Highlight: true, HighlightOptions: &V1HighlightOptions{Encoder: "xml"}})
+	assert.NotNil(t, err)
+}
+
+func TestV1HighlightWithoutSource(t *testing.T) {
+	index := v1TestIndex(t, "highlight-without-source")
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"title": "go search"}, Source: map[string]interface{}{"body": "long"}}))
+
+	query := &V1RequestQuery{
+		MultiMatch: &V1MultiMatch{Pattern: regexp.MustCompile("go"), Fields: []string{"title"}},
+	}
+	sourceEnabled := false
+	response, err := V1(nil, &V1Request{Index: index, Query: query, Highlight: true, SourceEnabled: &sourceEnabled})
+	assert.Nil(t, err)
+	if assert.Len(t, response.Hits.Hits, 1) {
+		hit := response.Hits.Hits[0]
+		assert.Equal(t, "1", hit.ID)
+		assert.Empty(t, hit.Source)
+		if assert.Len(t, hit.Highlights, 1) {
+			assert.Equal(t, "<em>go</em> search", hit.Highlights[0].Snippet)
+		}
+	}
+
+	// The source is returned by default
+	response, err = V1(nil, &V1Request{Index: index, Query: query, Highlight: true})
+	assert.Nil(t, err)
+	assert.Equal(t, "long", response.Hits.Hits[0].Source["body"])
+}
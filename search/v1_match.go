@@ -0,0 +1,269 @@
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	V1OperatorAnd = "and"
+	V1OperatorOr  = "or"
+)
+
+// v1MatchResult is the outcome of evaluating a query against a single doc
+type v1MatchResult struct {
+	Matched         bool
+	MatchedAndCount int
+	MatchedOrCount  int
+	// MultiMatchCount is the number of multi-match fields hit
+	MultiMatchCount int
+	// TermsAllCount is the number of fields containing all their terms
+	TermsAllCount int
+	// TermsCount is the number of Terms hit, MatchedTermsFields the number of fields matching under the operator
+	TermsCount         int
+	MatchedTermsFields int
+	// AnyFieldCount is the number of fields the any-field regex hits, only counted past the first
+	// when the query sorts by score
+	AnyFieldCount int
+}
+
+// V1MatchCounts are the clauses a hit matched, their sum is the unboosted score
+type V1MatchCounts struct {
+	And        int `json:"and"`
+	Or         int `json:"or"`
+	MultiMatch int `json:"multi_match"`
+	TermsAll   int `json:"terms_all"`
+	Terms      int `json:"terms"`
+	AnyField   int `json:"any_field"`
+}
+
+func (r v1MatchResult) counts() *V1MatchCounts {
+	return &V1MatchCounts{
+		And:        r.MatchedAndCount,
+		Or:         r.MatchedOrCount,
+		MultiMatch: r.MultiMatchCount,
+		TermsAll:   r.TermsAllCount,
+		Terms:      r.TermsCount,
+		AnyField:   r.AnyFieldCount,
+	}
+}
+
+// score counts every matched clause
+func (r v1MatchResult) score() int64 {
+	return int64(r.MatchedAndCount + r.MatchedOrCount + r.MultiMatchCount + r.TermsAllCount + r.TermsCount + r.AnyFieldCount)
+}
+
+// v1Match evaluates the query against the doc, recording every clause into explanation if it's not nil
+func v1Match(query *V1RequestQuery, doc *V1Doc, explanation *V1Explanation) v1MatchResult {
+	result := v1MatchResult{}
+
+	if query.SinceSeqNo > 0 {
+		matched := doc.SeqNo > query.SinceSeqNo
+		if explanation == nil && !matched {
+			return result
+		}
+
+		if explanation != nil {
+			explanation.add(V1ClauseSinceSeqNo, "_seq_no", strconv.FormatInt(query.SinceSeqNo, 10), strconv.FormatInt(doc.SeqNo, 10), true, matched)
+		}
+	}
+
+	if len(query.IDs) > 0 {
+		matched := v1ContainsString(query.IDs, doc.ID)
+		if explanation == nil && !matched {
+			return result
+		}
+
+		if explanation != nil {
+			explanation.add(V1ClauseIDs, "_id", strings.Join(query.IDs, ","), doc.ID, true, matched)
+		}
+	}
+
+	for k, reg := range query.RegsAnd {
+		v, found := doc.Keywords[k]
+		matched := found && reg != nil && reg.MatchString(v)
+		if matched {
+			result.MatchedAndCount++
+		}
+		explanation.add(V1ClauseRegsAnd, k, v1RegString(reg), v, found, matched)
+	}
+
+	for k, reg := range query.RegsOr {
+		v, found := doc.Keywords[k]
+		matched := found && reg != nil && reg.MatchString(v)
+		if matched {
+			result.MatchedOrCount++
+		}
+		explanation.add(V1ClauseRegsOr, k, v1RegString(reg), v, found, matched)
+	}
+
+	if multiMatch := query.MultiMatch; multiMatch != nil {
+		for _, k := range multiMatch.Fields {
+			v, found := doc.Keywords[k]
+			matched := found && multiMatch.Pattern != nil && multiMatch.Pattern.MatchString(v)
+			if matched {
+				result.MultiMatchCount++
+			}
+			explanation.add(V1ClauseMultiMatch, k, v1RegString(multiMatch.Pattern), v, found, matched)
+		}
+	}
+
+	for k, terms := range query.TermsAll {
+		v, found := doc.Keywords[k]
+		matched := found && v1HasAllTerms(doc, k, terms, query.synonyms)
+		if matched {
+			result.TermsAllCount++
+		}
+		if explanation != nil {
+			explanation.add(V1ClauseTermsAll, k, strings.Join(terms, " "), v, found, matched)
+		}
+	}
+
+	matchedRanges := 0
+	for k, r := range query.Ranges {
+		n, err := v1NumericValue(doc, k)
+		matched := err == nil && r != nil && r.contains(n)
+		if matched {
+			matchedRanges++
+		}
+		if explanation != nil {
+			explanation.add(V1ClauseRanges, k, r.String(), doc.Keywords[k], err == nil, matched)
+		}
+	}
+
+	if query.AnyField != nil {
+		scored := v1SortsBy(query.SortBys, V1SortByScore)
+
+		field, value := "", ""
+		for k, v := range doc.Keywords {
+			if !query.AnyField.MatchString(v) {
+				continue
+			}
+
+			result.AnyFieldCount++
+			if field == "" {
+				field, value = k, v
+			}
+
+			if !scored {
+				break
+			}
+		}
+		explanation.add(V1ClauseAnyField, field, query.AnyField.String(), value, field != "", result.AnyFieldCount > 0)
+	}
+
+	for k, terms := range query.Terms {
+		v, found := doc.Keywords[k]
+		count := v1CountTerms(doc, k, terms, query.synonyms)
+		result.TermsCount += count
+
+		matched := count > 0
+		if query.DefaultOperator == V1OperatorAnd {
+			matched = found && count == len(terms)
+		}
+		if matched {
+			result.MatchedTermsFields++
+		}
+
+		if explanation != nil {
+			explanation.add(V1ClauseTerms, k, strings.Join(terms, " "), v, found, matched)
+		}
+	}
+
+	matchedGeo := true
+	if geo := query.GeoDistance; geo != nil {
+		distance := v1GeoDistanceOf(doc, geo.Field, geo.Point)
+		matchedGeo = distance <= geo.DistanceKm
+		if explanation != nil {
+			v, found := doc.Keywords[geo.Field]
+			explanation.add(V1ClauseGeoDistance, geo.Field, "<="+strconv.FormatFloat(geo.DistanceKm, 'g', -1, 64)+"km from "+geo.Point.String(), v, found, matchedGeo)
+		}
+	}
+
+	matchedFilter := len(query.Filters) == 0
+	for k, filter := range query.Filters {
+		v, found := doc.Keywords[k]
+		matched := false
+		if found && len(filter) > 0 {
+			for _, f := range strings.Split(filter, ",") {
+				if r, ok := v1ParseFilterRange(f); ok {
+					n, err := v1NumericValue(doc, k)
+					matched = err == nil && r.contains(n)
+				} else {
+					matched = f == v
+				}
+				if matched {
+					break
+				}
+			}
+		}
+		if matched {
+			matchedFilter = true
+		}
+		explanation.add(V1ClauseFilters, k, filter, v, found, matched)
+	}
+
+	matchedAnd := result.MatchedAndCount == len(query.RegsAnd)
+	matchedOr := len(query.RegsOr) == 0 || result.MatchedOrCount > 0
+
+	matchedMultiMatch := query.MultiMatch == nil || result.MultiMatchCount > 0
+
+	matchedTermsAll := result.TermsAllCount == len(query.TermsAll)
+	matchedTerms := result.MatchedTermsFields == len(query.Terms)
+
+	matchedAnyField := query.AnyField == nil || result.AnyFieldCount > 0
+
+	matchedSeqNo := query.SinceSeqNo <= 0 || doc.SeqNo > query.SinceSeqNo
+	matchedIDs := len(query.IDs) == 0 || v1ContainsString(query.IDs, doc.ID)
+
+	result.Matched = matchedSeqNo && matchedIDs && matchedGeo && matchedRanges == len(query.Ranges) && matchedAnd && matchedOr && matchedMultiMatch && matchedTermsAll && matchedTerms && matchedAnyField && matchedFilter
+
+	return result
+}
+
+func v1ContainsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func v1RegString(reg *regexp.Regexp) string {
+	if reg == nil {
+		return ""
+	}
+
+	return reg.String()
+}
+
+// v1ValidateOperator rejects a DefaultOperator other than "and" or "or"
+func v1ValidateOperator(query *V1RequestQuery) error {
+	if query == nil {
+		return nil
+	}
+
+	switch query.DefaultOperator {
+	case "", V1OperatorAnd, V1OperatorOr:
+		return nil
+	default:
+		return fmt.Errorf("unknown default operator %s", query.DefaultOperator)
+	}
+}
+
+// v1SortsBy reports whether the field is one of the comma separated SortBys, compared whole like the sort does
+func v1SortsBy(sortBys, field string) bool {
+	for sortBys != "" {
+		var sortBy string
+		sortBy, sortBys, _ = strings.Cut(sortBys, ",")
+		if sortBy == field {
+			return true
+		}
+	}
+
+	return false
+}
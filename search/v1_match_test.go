@@ -0,0 +1,329 @@
+package search
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1MultiMatch(t *testing.T) {
+	index := v1TestIndex(t, "multi-match")
+
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"title": "golang", "body": "generics", "tags": "term"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2", Keywords: map[string]string{"title": "term", "body": "term", "tags": "term"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "3", Keywords: map[string]string{"title": "rust", "body": "traits", "tags": "lang"}}))
+
+	response, _ := V1(nil, &V1Request{
+		Index: index,
+		Query: &V1RequestQuery{
+			MultiMatch: &V1MultiMatch{
+				Pattern: regexp.MustCompile("term"),
+				Fields:  []string{"title", "body", "tags"},
+			},
+			SortBys: V1SortByScore,
+		},
+	})
+
+	if assert.Equal(t, 2, response.Hits.Total) {
+		// The doc hitting all three fields scores higher
+		assert.Equal(t, "2", response.Hits.Hits[0].ID)
+		assert.Equal(t, float64(3), response.Hits.Hits[0].Score)
+		assert.Equal(t, "1", response.Hits.Hits[1].ID)
+		assert.Equal(t, float64(1), response.Hits.Hits[1].Score)
+		assert.Equal(t, float64(3), response.Hits.MaxScore)
+	}
+}
+
+func TestV1TermsAll(t *testing.T) {
+	index := v1TestIndex(t, "terms-all")
+
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"body": "The quick brown fox"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2", Keywords: map[string]string{"body": "The quick red fox"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "3", Keywords: map[string]string{"body": "brown bear, quickly"}}))
+
+	response, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{TermsAll: map[string][]string{"body": {"quick", "Brown"}}}})
+	if assert.Equal(t, 1, response.Hits.Total) {
+		assert.Equal(t, "1", response.Hits.Hits[0].ID)
+	}
+
+	response, _ = V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{TermsAll: map[string][]string{"body": {"fox", "the"}}}})
+	assert.Equal(t, 2, response.Hits.Total)
+
+	// Terms match whole tokens only
+	response, _ = V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{TermsAll: map[string][]string{"body": {"bro"}}}})
+	assert.Equal(t, 0, response.Hits.Total)
+
+	response, _ = V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{TermsAll: map[string][]string{"title": {"fox"}}}})
+	assert.Equal(t, 0, response.Hits.Total)
+}
+
+func TestV1AnyField(t *testing.T) {
+	index := v1TestIndex(t, "any-field")
+
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"title": "gopher", "notes": "mascot"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2", Keywords: map[string]string{"title": "ferris", "owner": "crab mascot", "alias": "mascot"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "3", Keywords: map[string]string{"title": "duke"}}))
+
+	response, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{AnyField: regexp.MustCompile("crab")}})
+	if assert.Equal(t, 1, response.Hits.Total) {
+		assert.Equal(t, "2", response.Hits.Hits[0].ID)
+	}
+
+	// Every hit field counts when scoring
+	response, _ = V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{AnyField: regexp.MustCompile("mascot"), SortBys: V1SortByScore}})
+	if assert.Equal(t, 2, response.Hits.Total) {
+		assert.Equal(t, "2", response.Hits.Hits[0].ID)
+		assert.Equal(t, float64(2), response.Hits.Hits[0].Score)
+		assert.Equal(t, float64(1), response.Hits.Hits[1].Score)
+	}
+
+	// A sort field merely containing "_score" doesn't score
+	response, _ = V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{AnyField: regexp.MustCompile("mascot"), SortBys: "my_score"}})
+	if assert.Equal(t, 2, response.Hits.Total) {
+		assert.Equal(t, float64(1), response.Hits.Hits[0].Score)
+		assert.Equal(t, float64(1), response.Hits.Hits[1].Score)
+	}
+	assert.True(t, v1SortsBy("name,_score", V1SortByScore))
+	assert.False(t, v1SortsBy("_score_bucket,my_score", V1SortByScore))
+}
+
+func TestV1TermsDefaultOperator(t *testing.T) {
+	index := v1TestIndex(t, "terms-default-operator")
+
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"title": "red apple pie"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2", Keywords: map[string]string{"title": "green apple"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "3", Keywords: map[string]string{"title": "cherry pie"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "4", Keywords: map[string]string{"title": "banana"}}))
+
+	terms := map[string][]string{"title": {"apple", "pie"}}
+
+	// All words
+	response, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{Terms: terms, DefaultOperator: V1OperatorAnd}})
+	if assert.Equal(t, 1, response.Hits.Total) {
+		assert.Equal(t, "1", response.Hits.Hits[0].ID)
+	}
+
+	// Any word, docs hitting more terms score higher
+	response, _ = V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{Terms: terms, DefaultOperator: V1OperatorOr, SortBys: V1SortByScore}})
+	if assert.Equal(t, 3, response.Hits.Total) {
+		assert.Equal(t, "1", response.Hits.Hits[0].ID)
+		assert.Equal(t, float64(2), response.Hits.Hits[0].Score)
+		assert.Equal(t, float64(1), response.Hits.Hits[1].Score)
+	}
+
+	// Or is the default
+	response, _ = V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{Terms: terms}})
+	assert.Equal(t, 3, response.Hits.Total)
+
+	// Anything else is rejected rather than read as or
+	for _, operator := range []string{"AND", "all", "adn"} {
+		_, err := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{Terms: terms, DefaultOperator: operator}})
+		assert.NotNil(t, err, operator)
+	}
+	_, err := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{}, PostFilter: &V1RequestQuery{DefaultOperator: "xor"}})
+	assert.NotNil(t, err)
+}
+
+func TestV1BoostValue(t *testing.T) {
+	index := v1TestIndex(t, "boost-value")
+
+	// Both match the query equally, without the boost the tie would go to the larger SortableID
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"title": "golang"}, BoostValue: 1.5}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2", Keywords: map[string]string{"title": "golang"}}))
+	assert.NotNil(t, V1Put(nil, &V1Request{Index: index, ID: "3", BoostValue: -1}))
+
+	response, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{
+		RegsOr:   map[string]*regexp.Regexp{"title": regexp.MustCompile("go")},
+		SortBys:  V1SortByScore,
+		SortMode: "desc",
+	}})
+
+	if assert.Equal(t, 2, response.Hits.Total) {
+		assert.Equal(t, "1", response.Hits.Hits[0].ID)
+		assert.Equal(t, 1.5, response.Hits.Hits[0].Score)
+		assert.Equal(t, "2", response.Hits.Hits[1].ID)
+		assert.Equal(t, float64(1), response.Hits.Hits[1].Score)
+		assert.Equal(t, 1.5, response.Hits.MaxScore)
+	}
+}
+
+func TestV1IDs(t *testing.T) {
+	index := v1TestIndex(t, "ids")
+	for i, color := range []string{"red", "blue", "red", "red", "blue"} {
+		assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: strconv.Itoa(i + 1), Keywords: map[string]string{"color": color}}))
+	}
+
+	// Unknown and repeated IDs are fine
+	query := &V1RequestQuery{
+		IDs:      []string{"1", "2", "4", "4", "404"},
+		Filters:  map[string]string{"color": "red"},
+		SortMode: "asc",
+	}
+	response, _ := V1(nil, &V1Request{Index: index, Query: query})
+	if assert.Equal(t, 2, response.Hits.Total) {
+		assert.Equal(t, "1", response.Hits.Hits[0].ID)
+		assert.Equal(t, "4", response.Hits.Hits[1].ID)
+	}
+
+	// The clause holds outside of the lookup too
+	response, _ = V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{Filters: map[string]string{"color": "red"}}, PostFilter: &V1RequestQuery{IDs: []string{"3"}}})
+	if assert.Equal(t, 1, response.Hits.Total) {
+		assert.Equal(t, "3", response.Hits.Hits[0].ID)
+	}
+
+	explanation, err := V1ExplainDoc(nil, index, "3", query)
+	assert.Nil(t, err)
+	assert.False(t, explanation.Matched)
+	assert.Equal(t, V1ClauseIDs, explanation.Clauses[1].Clause)
+	assert.False(t, explanation.Clauses[1].Matched)
+}
+
+func TestV1ReturnMatchCounts(t *testing.T) {
+	index := v1TestIndex(t, "match-counts")
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"title": "go", "lang": "go", "body": "gopher"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2", Keywords: map[string]string{"title": "go", "lang": "rust", "body": "crab"}}))
+
+	request := &V1Request{Index: index, ReturnMatchCounts: true, Query: &V1RequestQuery{
+		RegsAnd: map[string]*regexp.Regexp{"title": regexp.MustCompile("go")},
+		RegsOr: map[string]*regexp.Regexp{
+			"lang": regexp.MustCompile("go"),
+			"body": regexp.MustCompile("gopher"),
+		},
+		Terms:    map[string][]string{"title": {"go"}},
+		SortBys:  V1SortByScore,
+		SortMode: "desc",
+	}}
+
+	response, _ := V1(nil, request)
+	if assert.Equal(t, 1, response.Hits.Total) {
+		assert.Equal(t, &V1MatchCounts{And: 1, Or: 2, Terms: 1}, response.Hits.Hits[0].MatchCounts)
+		assert.Equal(t, float64(4), response.Hits.Hits[0].Score)
+	}
+
+	request.Query.RegsOr["body"] = regexp.MustCompile("crab")
+	response, _ = V1(nil, request)
+	if assert.Equal(t, 2, response.Hits.Total) {
+		for _, hit := range response.Hits.Hits {
+			assert.Equal(t, &V1MatchCounts{And: 1, Or: 1, Terms: 1}, hit.MatchCounts)
+		}
+	}
+
+	request.ReturnMatchCounts = false
+	response, _ = V1(nil, request)
+	assert.Nil(t, response.Hits.Hits[0].MatchCounts)
+}
+
+func TestV1ScoreCombiner(t *testing.T) {
+	index := v1TestIndex(t, "score-combiner")
+	for _, id := range []string{"1", "2", "3", "4"} {
+		assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: id, Keywords: map[string]string{"title": "golang"}}))
+	}
+
+	external := map[string]float64{"1": 3, "2": -1, "3": 0.5}
+	for _, c := range []struct {
+		combiner string
+		weight   float64
+		ids      []string
+		scores   []float64
+	}{
+		{"", 0, []string{"1", "3", "4", "2"}, []float64{4, 1.5, 1, 0}},
+		{V1CombineSum, 0, []string{"1", "3", "4", "2"}, []float64{4, 1.5, 1, 0}},
+		{V1CombineMax, 0, []string{"1", "4", "3", "2"}, []float64{3, 1, 1, 1}},
+		{V1CombineMultiply, 0, []string{"1", "4", "3", "2"}, []float64{3, 1, 0.5, -1}},
+		{V1CombineWeighted, 0.5, []string{"1", "4", "3", "2"}, []float64{2, 1, 0.75, 0}},
+	} {
+		response, _ := V1(nil, &V1Request{
+			Index:          index,
+			Query:          &V1RequestQuery{RegsOr: map[string]*regexp.Regexp{"title": regexp.MustCompile("go")}, SortBys: V1SortByScore},
+			ExternalScores: external,
+			ScoreCombiner:  c.combiner,
+			ExternalWeight: c.weight,
+		})
+
+		ids, scores := make([]string, 0), make([]float64, 0)
+		for _, hit := range response.Hits.Hits {
+			ids = append(ids, hit.ID)
+			scores = append(scores, hit.Score)
+		}
+		assert.Equal(t, c.ids, ids, c.combiner)
+		assert.Equal(t, c.scores, scores, c.combiner)
+	}
+
+	_, err := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{}, ScoreCombiner: "avg"})
+	assert.NotNil(t, err)
+	_, err = V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{}, ScoreCombiner: V1CombineWeighted, ExternalWeight: 2})
+	assert.NotNil(t, err)
+}
+
+func TestV1FilterRanges(t *testing.T) {
+	index := v1TestIndex(t, "filter-ranges")
+	for i, price := range []string{"2", "3", "5.5", "10", "100", "250", "n/a"} {
+		assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: strconv.Itoa(i + 1), Keywords: map[string]string{"price": price}}))
+	}
+
+	ids := func(filter string) []string {
+		response, err := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{Filters: map[string]string{"price": filter}}, Size: 10})
+		assert.Nil(t, err)
+		ids := make([]string, 0, len(response.Hits.Hits))
+		for _, hit := range response.Hits.Hits {
+			ids = append(ids, hit.Source["price"].(string))
+		}
+		sort.Strings(ids)
+		return ids
+	}
+
+	assert.Equal(t, []string{"10", "100"}, ids("[10 TO 100]"))
+	assert.Equal(t, []string{"10", "100", "250", "5.5"}, ids(">5"))
+	assert.Equal(t, []string{"2", "3"}, ids("<=3"))
+	assert.Equal(t, []string{"10", "5.5"}, ids("{3 TO 100}"))
+	assert.Equal(t, []string{"100", "250"}, ids("[100 TO *]"))
+
+	// Ranges and exact values combine, anything else is matched as a string
+	assert.Equal(t, []string{"2", "250"}, ids("<3,250"))
+	assert.Equal(t, []string{"n/a"}, ids("n/a"))
+	assert.Equal(t, []string{}, ids("[10 TO"))
+}
+
+func TestV1Decay(t *testing.T) {
+	index := v1TestIndex(t, "decay")
+	now := time.Now().Truncate(time.Second)
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"title": "go news", "published": now.Add(-time.Hour).Format(time.RFC3339)}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2", Keywords: map[string]string{"title": "go news", "published": strconv.FormatInt(now.Add(-72*time.Hour).Unix(), 10)}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "3", Keywords: map[string]string{"title": "go news"}}))
+
+	query := &V1RequestQuery{RegsAnd: map[string]*regexp.Regexp{"title": regexp.MustCompile("go")}, SortBys: V1SortByScore}
+
+	// Without a decay the ids break the tie
+	response, _ := V1(nil, &V1Request{Index: index, Query: query})
+	assert.Equal(t, []string{"3", "2", "1"}, []string{response.Hits.Hits[0].ID, response.Hits.Hits[1].ID, response.Hits.Hits[2].ID})
+
+	// The newer doc outranks the older one, a doc without a date keeps its score
+	response, err := V1(nil, &V1Request{Index: index, Query: query, Decay: &V1Decay{Field: "published", Scale: 24 * time.Hour, Func: V1DecayExp}})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"3", "1", "2"}, []string{response.Hits.Hits[0].ID, response.Hits.Hits[1].ID, response.Hits.Hits[2].ID})
+	assert.InDelta(t, 1, response.Hits.Hits[0].Score, 1e-9)
+	assert.InDelta(t, math.Pow(0.5, 1.0/24), response.Hits.Hits[1].Score, 1e-3)
+	assert.InDelta(t, 0.125, response.Hits.Hits[2].Score, 1e-3)
+
+	// Combined with the boost and the external scores
+	response, _ = V1(nil, &V1Request{Index: index, Query: query, Decay: &V1Decay{Field: "published", Scale: 24 * time.Hour}, ExternalScores: map[string]float64{"2": 1}})
+	assert.Equal(t, "2", response.Hits.Hits[0].ID)
+	assert.InDelta(t, 1.125, response.Hits.Hits[0].Score, 1e-3)
+
+	doc := &V1Doc{ModifiedAt: now.Add(-48 * time.Hour).Unix()}
+	assert.InDelta(t, 1, (&V1Decay{Scale: 24 * time.Hour, Offset: 48 * time.Hour}).factor(doc, now), 1e-9)
+	assert.InDelta(t, 0.25, (&V1Decay{Scale: 24 * time.Hour}).factor(doc, now), 1e-9)
+	assert.InDelta(t, 0, (&V1Decay{Scale: 24 * time.Hour, Func: V1DecayLinear}).factor(doc, now), 1e-9)
+	assert.InDelta(t, 0.0625, (&V1Decay{Scale: 24 * time.Hour, Func: V1DecayGauss}).factor(doc, now), 1e-9)
+	assert.InDelta(t, 0.75, (&V1Decay{Scale: 96 * time.Hour, Func: V1DecayLinear}).factor(doc, now), 1e-9)
+
+	for _, decay := range []*V1Decay{{}, {Scale: time.Hour, Offset: -time.Hour}, {Scale: time.Hour, Func: "cubic"}} {
+		_, err := V1(nil, &V1Request{Index: index, Query: query, Decay: decay})
+		assert.NotNil(t, err)
+	}
+}
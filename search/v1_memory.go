@@ -0,0 +1,290 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// v1MemoryBudget is the soft limit of the estimated bytes of every index, 0 means unlimited,
+	// guarded by v1IndexLock along with v1SpillDir
+	v1MemoryBudget int64
+	// v1SpillDir is where the shed indices are snapshotted to, they're dropped for good if it's empty
+	v1SpillDir string
+
+	v1SpillLock = &sync.Mutex{}
+	v1Spills    = make(map[string]*v1Spill)
+)
+
+// v1Spill is an index shed to a snapshot file, reloaded once by the first lookup along with
+// what the snapshot doesn't hold
+type v1Spill struct {
+	path     string
+	synonyms map[string][]string
+	onEvict  func(*V1Doc)
+	auto     *v1AutoSnapshot
+	once     sync.Once
+	err      error
+}
+
+// v1MemoryCandidate is an index as it was when V1ShedMemory measured it
+type v1MemoryCandidate struct {
+	name       string
+	bytes      int64
+	lastAccess int64
+	seqNo      int64
+}
+
+// V1SetMemoryBudget sets the soft limit of the summed memory estimates of the indices, 0 (the default) disables it.
+// Over budget, V1ShedMemory drops the least recently used indices, snapshotting them to spillDir first if it's
+// set, so the next query or write reloads them. Without it their docs are gone, like an LRU eviction
+func V1SetMemoryBudget(budget int64, spillDir string) error {
+	if budget < 0 {
+		return fmt.Errorf("invalid memory budget %d", budget)
+	}
+
+	if spillDir != "" {
+		if err := os.MkdirAll(spillDir, 0o755); err != nil {
+			return fmt.Errorf("create spill dir: %w", err)
+		}
+	}
+
+	v1IndexLock.Lock()
+	defer v1IndexLock.Unlock()
+
+	v1MemoryBudget = budget
+	v1SpillDir = spillDir
+
+	return nil
+}
+
+// V1MemoryUsage returns the estimated bytes of each index, by the serialized source of its docs
+func V1MemoryUsage(ctx *gin.Context) map[string]int64 {
+	usage := make(map[string]int64)
+	for _, candidate := range v1MemoryCandidates() {
+		usage[candidate.name] = candidate.bytes
+	}
+
+	return usage
+}
+
+// memoryBytes estimates the bytes held by the docs of the index, the soft-deleted ones included. It's kept
+// up to date by the writes, so measuring is cheap under the locks. The caller must hold the lock
+func (w *v1IndexWrapper) memoryBytes() int64 {
+	return w.bytes
+}
+
+// v1MemoryCandidates measures every index, the replicas included
+func v1MemoryCandidates() []*v1MemoryCandidate {
+	v1IndexLock.RLock()
+	defer v1IndexLock.RUnlock()
+
+	candidates := make([]*v1MemoryCandidate, 0, len(v1IndexMapping))
+	for name, offset := range v1IndexMapping {
+		w := v1Indices[offset]
+
+		w.Lock.RLock()
+		candidates = append(candidates, &v1MemoryCandidate{
+			name:       name,
+			bytes:      w.memoryBytes(),
+			lastAccess: atomic.LoadInt64(&w.lastAccess),
+			seqNo:      w.seqNo,
+		})
+		w.Lock.RUnlock()
+	}
+
+	return candidates
+}
+
+// V1ShedMemory drops the least recently used indices until the estimates fit the memory budget and returns
+// their names, coldest first. Replicas aren't shed, their refreshers would fail, nor are the indices with
+// soft-deleted docs while spilling, they couldn't be undeleted. It returns the first error snapshotting
+// an index to the spill dir, that index is kept
+func V1ShedMemory(ctx *gin.Context) ([]string, error) {
+	v1IndexLock.RLock()
+	budget, dir := v1MemoryBudget, v1SpillDir
+	v1IndexLock.RUnlock()
+
+	if budget <= 0 {
+		return nil, nil
+	}
+
+	candidates := v1MemoryCandidates()
+
+	var total int64
+	for _, candidate := range candidates {
+		total += candidate.bytes
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].lastAccess < candidates[j].lastAccess
+	})
+
+	shed := make([]string, 0)
+	var first error
+	for _, candidate := range candidates {
+		if total <= budget {
+			break
+		}
+
+		// Halted ahead, so a tick doesn't reload the index once it's shed
+		auto := v1TakeAutoSnapshot(candidate.name)
+		auto.halt()
+
+		var spill *v1Spill
+		if dir != "" {
+			spill = &v1Spill{path: V1SnapshotPath(dir, candidate.name), auto: auto}
+			if err := V1SnapshotToFile(ctx, candidate.name, spill.path); err != nil {
+				if first == nil {
+					first = err
+				}
+				auto.resume(ctx, candidate.name)
+				continue
+			}
+		}
+
+		if !v1Shed(candidate, spill) {
+			auto.resume(ctx, candidate.name)
+			continue
+		}
+
+		v1Emit(&V1ChangeEvent{Type: V1EventIndexEvicted, Index: candidate.name})
+
+		total -= candidate.bytes
+		shed = append(shed, candidate.name)
+	}
+
+	return shed, first
+}
+
+// v1Shed drops the index unless it's a replica or was written since it was measured, so the snapshot of the spill
+// misses nothing. The spill is recorded along with the drop, so no lookup sees the index missing in between
+func v1Shed(candidate *v1MemoryCandidate, spill *v1Spill) bool {
+	v1IndexLock.Lock()
+	offset, found := v1IndexMapping[candidate.name]
+	if !found {
+		v1IndexLock.Unlock()
+		return false
+	}
+
+	w := v1Indices[offset]
+
+	w.Lock.Lock()
+	if w.readOnly || w.seqNo != candidate.seqNo || (spill != nil && len(w.trash) > 0) {
+		w.Lock.Unlock()
+		v1IndexLock.Unlock()
+		return false
+	}
+
+	if spill != nil {
+		spill.synonyms = w.synonyms
+		spill.onEvict = w.Config.OnEvict
+	}
+
+	evictions := w.drop()
+	if spill != nil {
+		// The docs aren't gone, they're reloaded
+		evictions = nil
+
+		v1SpillLock.Lock()
+		v1Spills[candidate.name] = spill
+		v1SpillLock.Unlock()
+	}
+	w.Lock.Unlock()
+	v1IndexLock.Unlock()
+
+	evictions.notify()
+
+	return true
+}
+
+// V1StartMemoryWatcher sheds the indices over the memory budget periodically until stop or V1Shutdown is called
+func V1StartMemoryWatcher(interval time.Duration) (stop func(), err error) {
+	w, err := v1StartWorker(interval, func() {
+		V1ShedMemory(nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return w.stop, nil
+}
+
+// v1Reload loads the spilled index back into a slot and reports whether it did, the concurrent
+// lookups of the index wait for the first one to load it
+func v1Reload(index string) bool {
+	v1SpillLock.Lock()
+	spill, found := v1Spills[index]
+	v1SpillLock.Unlock()
+
+	if !found {
+		return false
+	}
+
+	spill.once.Do(func() {
+		spill.err = spill.load(index)
+	})
+
+	return spill.err == nil
+}
+
+// load creates the index from the spill file, the spill is forgotten once the index is visible
+// and kept for a later lookup to retry if it fails
+func (s *v1Spill) load(index string) error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return s.retry(index, err)
+	}
+	snapshot, err := v1DecodeSnapshot(index, f)
+	f.Close()
+	if err != nil {
+		return s.retry(index, err)
+	}
+
+	err = v1CreateIndex(nil, index, func(w *v1IndexWrapper) {
+		w.restore(snapshot)
+		w.Config.OnEvict = s.onEvict
+		w.synonyms = s.synonyms
+	})
+	// An index created in between wins over the spill
+	if err != nil && !errors.Is(err, ErrIndexExists) {
+		return s.retry(index, err)
+	}
+
+	v1SpillLock.Lock()
+	if v1Spills[index] == s {
+		delete(v1Spills, index)
+	}
+	v1SpillLock.Unlock()
+
+	os.Remove(s.path)
+
+	s.auto.resume(nil, index)
+
+	return nil
+}
+
+// retry replaces the failed spill with a fresh one, as its once is spent
+func (s *v1Spill) retry(index string, err error) error {
+	v1SpillLock.Lock()
+	if v1Spills[index] == s {
+		v1Spills[index] = &v1Spill{path: s.path, synonyms: s.synonyms, onEvict: s.onEvict, auto: s.auto}
+	}
+	v1SpillLock.Unlock()
+
+	return err
+}
+
+// v1ForgetSpill drops the spill of the index, its file is left in place
+func v1ForgetSpill(index string) {
+	v1SpillLock.Lock()
+	delete(v1Spills, index)
+	v1SpillLock.Unlock()
+}
@@ -0,0 +1,166 @@
+package search
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1ShedMemory(t *testing.T) {
+	cold := v1TestIndex(t, "memory-cold")
+	hot := v1TestIndex(t, "memory-hot")
+
+	fill := func() {
+		for _, id := range []string{"1", "2"} {
+			assert.Nil(t, V1Put(nil, &V1Request{Index: cold, ID: id, Keywords: map[string]string{"name": "cold " + id}}))
+			assert.Nil(t, V1Put(nil, &V1Request{Index: hot, ID: id, Keywords: map[string]string{"name": "hot " + id}}))
+		}
+	}
+
+	// Sets a budget just under the usage, so only the coldest index is shed
+	shed := func(spillDir string) {
+		for i := 0; i < v1IndexCapacity; i++ {
+			if v1Indices[i].Initialized {
+				v1Indices[i].touch()
+			}
+		}
+		v1Indices[V1GetIndexMapping(cold)].lastAccess = 0
+
+		var total int64
+		for _, bytes := range V1MemoryUsage(nil) {
+			total += bytes
+		}
+		assert.Nil(t, V1SetMemoryBudget(total-1, spillDir))
+
+		shed, err := V1ShedMemory(nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{cold}, shed)
+	}
+	defer V1SetMemoryBudget(0, "")
+
+	total := func(index string) int {
+		response, err := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{}})
+		if !assert.Nil(t, err) {
+			return -1
+		}
+		return response.Hits.Total
+	}
+
+	assert.NotNil(t, V1SetMemoryBudget(-1, ""))
+
+	// With a spill dir the shed index is reloaded by the next query, along with what the snapshot doesn't hold
+	dir := t.TempDir()
+	fill()
+	evicted := 0
+	assert.Nil(t, V1SetIndexConfig(nil, cold, V1IndexConfig{OnEvict: func(*V1Doc) { evicted++ }}))
+	assert.Nil(t, V1PutSynonyms(nil, cold, [][]string{{"chilly 1", "cold 1"}}))
+	assert.Nil(t, V1EnableAutoSnapshot(nil, cold, t.TempDir(), time.Hour))
+	defer V1DisableAutoSnapshot(nil, cold)
+	synonym := func() int {
+		response, err := V1(nil, &V1Request{Index: cold, Query: &V1RequestQuery{Filters: map[string]string{"name": "chilly 1"}}})
+		if !assert.Nil(t, err) {
+			return -1
+		}
+		return response.Hits.Total
+	}
+	assert.Equal(t, 1, synonym())
+	shed(dir)
+	v1IndexLock.RLock()
+	_, loaded := v1IndexMapping[cold]
+	v1IndexLock.RUnlock()
+	assert.False(t, loaded)
+	_, err := os.Stat(V1SnapshotPath(dir, cold))
+	assert.Nil(t, err)
+
+	assert.Equal(t, 2, total(cold))
+	assert.Equal(t, 2, total(hot))
+	_, err = os.Stat(V1SnapshotPath(dir, cold))
+	assert.True(t, os.IsNotExist(err))
+	assert.Equal(t, 1, synonym())
+	v1AutoSnapshotLock.Lock()
+	_, scheduled := v1AutoSnapshots[cold]
+	v1AutoSnapshotLock.Unlock()
+	assert.True(t, scheduled)
+	assert.Nil(t, V1Delete(nil, cold, "2"))
+	assert.Equal(t, 1, evicted)
+	assert.Nil(t, V1Put(nil, &V1Request{Index: cold, ID: "2", Keywords: map[string]string{"name": "cold 2"}}))
+
+	// Writes reload it too
+	shed(dir)
+	assert.Nil(t, V1Put(nil, &V1Request{Index: cold, ID: "3"}))
+	assert.Equal(t, 3, total(cold))
+
+	// An index with soft-deleted docs isn't spilled, they couldn't be undeleted
+	trash := v1TestIndex(t, "memory-trash")
+	assert.Nil(t, V1SetIndexConfig(nil, trash, V1IndexConfig{SoftDeleteWindow: time.Hour}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: trash, ID: "1"}))
+	assert.Nil(t, V1Delete(nil, trash, "1"))
+	for _, candidate := range v1MemoryCandidates() {
+		if candidate.name == trash {
+			assert.False(t, v1Shed(candidate, &v1Spill{path: V1SnapshotPath(dir, trash)}))
+		}
+	}
+	assert.Nil(t, V1Undelete(nil, trash, "1"))
+
+	// Without it the docs are gone
+	v1DropIndex(cold)
+	fill()
+	shed("")
+	_, err = V1(nil, &V1Request{Index: cold, Query: &V1RequestQuery{}})
+	assert.True(t, errors.Is(err, ErrIndexNotFound))
+	assert.Equal(t, 2, total(hot))
+
+	// Nothing is shed within budget
+	assert.Nil(t, V1SetMemoryBudget(1<<40, dir))
+	shedIndices, err := V1ShedMemory(nil)
+	assert.Nil(t, err)
+	assert.Empty(t, shedIndices)
+}
+
+func TestV1MemoryUsage(t *testing.T) {
+	index := v1TestIndex(t, "memory-usage")
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{SoftDeleteWindow: time.Hour}))
+
+	// The usage is kept by the writes, it must add up to what the docs hold
+	measured := func() int64 {
+		w := v1Indices[V1GetIndexMapping(index)]
+		w.Lock.RLock()
+		defer w.Lock.RUnlock()
+
+		var total int64
+		for _, doc := range w.all() {
+			bytes, _ := v1EstimateDocBytes(doc.Source)
+			total += bytes
+		}
+		return total
+	}
+	usage := func() int64 {
+		return V1MemoryUsage(nil)[index]
+	}
+
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Source: map[string]interface{}{"name": "a"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2", Source: map[string]interface{}{"name": "b"}}))
+	assert.Equal(t, measured(), usage())
+	assert.Greater(t, usage(), int64(0))
+
+	// Updates replace the size of the doc
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Source: map[string]interface{}{"name": "a longer name"}}))
+	assert.Equal(t, measured(), usage())
+
+	// Soft-deleted docs still count until they leave the trash
+	before := usage()
+	assert.Nil(t, V1Delete(nil, index, "1"))
+	assert.Equal(t, before, usage())
+	assert.Nil(t, V1Undelete(nil, index, "1"))
+	assert.Equal(t, before, usage())
+	assert.Nil(t, V1Delete(nil, index, "2"))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2"}))
+	assert.Nil(t, V1Delete(nil, index, "2"))
+	assert.Equal(t, measured(), usage())
+
+	assert.Nil(t, V1Reset(nil, index))
+	assert.Equal(t, int64(0), usage())
+}
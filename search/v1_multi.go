@@ -0,0 +1,64 @@
+package search
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// V1IndexFailure is an index a query over Indices failed on
+type V1IndexFailure struct {
+	Index string `json:"index"`
+	Error string `json:"error"`
+}
+
+// v1UniqueIndices drops the indices listed before, directly or through an alias
+func v1UniqueIndices(indices []string) []string {
+	seen := make(map[string]bool, len(indices))
+	unique := make([]string, 0, len(indices))
+	for _, index := range indices {
+		if resolved := v1ResolveAlias(index); !seen[resolved] {
+			seen[resolved] = true
+			unique = append(unique, index)
+		}
+	}
+
+	return unique
+}
+
+// v1ScanIndices scans the indices by a pool of request.Parallelism workers, the scans are in the order of indices
+func v1ScanIndices(request *V1Request, indices []string, now time.Time, done <-chan struct{}, deadline time.Time) []*v1Scan {
+	scans := make([]*v1Scan, len(indices))
+	if len(indices) == 1 {
+		scans[0] = v1ScanIndex(request, indices[0], now, done, deadline)
+		return scans
+	}
+
+	parallelism := request.Parallelism
+	if parallelism <= 0 {
+		parallelism = runtime.GOMAXPROCS(0)
+	}
+	if parallelism > len(indices) {
+		parallelism = len(indices)
+	}
+
+	jobs := make(chan int)
+	wg := &sync.WaitGroup{}
+	for w := 0; w < parallelism; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				scans[i] = v1ScanIndex(request, indices[i], now, done, deadline)
+			}
+		}()
+	}
+
+	for i := range indices {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return scans
+}
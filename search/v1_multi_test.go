@@ -0,0 +1,102 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// v1TestIndices creates n indices of docs docs each, the ids repeat across them
+func v1TestIndices(tb testing.TB, name string, n, docs int) []string {
+	indices := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		index := fmt.Sprintf("%s-%d", name, i)
+		tb.Cleanup(func() {
+			v1DropIndex(index)
+		})
+
+		for j := 0; j < docs; j++ {
+			err := V1Put(nil, &V1Request{Index: index, ID: strconv.Itoa(j + 1), Keywords: map[string]string{
+				"color": []string{"red", "green", "blue"}[(i+j)%3],
+				"size":  strconv.Itoa((i * j) % 7),
+			}})
+			if err != nil {
+				tb.Fatal(err)
+			}
+		}
+		indices = append(indices, index)
+	}
+
+	return indices
+}
+
+func TestV1MultiIndex(t *testing.T) {
+	indices := v1TestIndices(t, "multi", 4, 20)
+
+	request := func(parallelism int) *V1Request {
+		return &V1Request{
+			Indices:     indices,
+			Parallelism: parallelism,
+			Query:       &V1RequestQuery{Filters: map[string]string{"color": "red,blue"}, SortBys: "size", SortMode: "asc"},
+			Facets:      &V1Facets{Fields: []string{"color"}},
+			Size:        15,
+		}
+	}
+
+	sequential, err := V1(nil, request(1))
+	assert.Nil(t, err)
+	assert.Equal(t, 53, sequential.Hits.Total)
+	for _, parallelism := range []int{0, 2, 4, 16} {
+		parallel, err := V1(nil, request(parallelism))
+		assert.Nil(t, err)
+		parallel.Took = sequential.Took
+		assert.Equal(t, sequential, parallel, "parallelism %d", parallelism)
+	}
+
+	// Paging walks the merged hits, the same id from several indices included
+	seen := make(map[string]bool)
+	page := request(2)
+	for {
+		response, err := V1(nil, page)
+		assert.Nil(t, err)
+		for _, hit := range response.Hits.Hits {
+			seen[hit.Index+"/"+hit.ID] = true
+		}
+		if response.NextPageToken == "" {
+			break
+		}
+		page.PageToken = response.NextPageToken
+	}
+	assert.Len(t, seen, 53)
+}
+
+func TestV1MultiIndexFailures(t *testing.T) {
+	indices := v1TestIndices(t, "multi-failures", 2, 5)
+
+	response, err := V1(nil, &V1Request{Indices: append(indices, "multi-failures-missing", indices[0]), Query: &V1RequestQuery{}, Size: 20})
+	assert.Nil(t, err)
+	assert.Equal(t, 10, response.Hits.Total)
+	if assert.Len(t, response.Failures, 1) {
+		assert.Equal(t, "multi-failures-missing", response.Failures[0].Index)
+		assert.NotEmpty(t, response.Failures[0].Error)
+	}
+
+	// It only fails if every index does
+	_, err = V1(nil, &V1Request{Indices: []string{"multi-failures-missing", "multi-failures-gone"}, Query: &V1RequestQuery{}})
+	assert.True(t, errors.Is(err, ErrIndexNotFound))
+}
+
+func BenchmarkV1MultiIndex(b *testing.B) {
+	indices := v1TestIndices(b, "bench-multi", 16, 2000)
+
+	for _, parallelism := range []int{1, 0} {
+		b.Run(fmt.Sprintf("parallelism=%d", parallelism), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				V1(nil, &V1Request{Indices: indices, Parallelism: parallelism, Query: &V1RequestQuery{Filters: map[string]string{"color": "red"}}})
+			}
+		})
+	}
+}
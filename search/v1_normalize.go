@@ -0,0 +1,125 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	V1NormalizerTrim               = "trim"
+	V1NormalizerLowercase          = "lowercase"
+	V1NormalizerCollapseWhitespace = "collapse_whitespace"
+	// V1NormalizerFold strips accents and folds case, so "Café" and "cafe" are the same
+	V1NormalizerFold = "fold"
+)
+
+var v1Normalizers = map[string]func(string) string{
+	V1NormalizerTrim:      strings.TrimSpace,
+	V1NormalizerLowercase: strings.ToLower,
+	V1NormalizerCollapseWhitespace: func(v string) string {
+		return strings.Join(strings.Fields(v), " ")
+	},
+	V1NormalizerFold: v1Fold,
+}
+
+// v1Fold removes the diacritics and folds the case, the transformers are stateful so they're built per call
+func v1Fold(v string) string {
+	stripped, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), v)
+	if err != nil {
+		stripped = v
+	}
+
+	return cases.Fold().String(stripped)
+}
+
+// v1ValidateNormalizers rejects unknown normalizer names
+func v1ValidateNormalizers(normalizers map[string][]string) error {
+	for field, names := range normalizers {
+		for _, name := range names {
+			if _, found := v1Normalizers[name]; !found {
+				return fmt.Errorf("unknown normalizer %s for field %s", name, field)
+			}
+		}
+	}
+
+	return nil
+}
+
+// v1Normalize applies the field's normalizers in order, then the index-wide folding
+func v1Normalize(config V1IndexConfig, field, value string) string {
+	for _, name := range config.Normalizers[field] {
+		value = v1Normalizers[name](value)
+	}
+
+	if config.Folding {
+		value = v1Fold(value)
+	}
+
+	return value
+}
+
+// v1NormalizeKeywords returns a normalized copy of the keywords
+func v1NormalizeKeywords(config V1IndexConfig, keywords map[string]string) map[string]string {
+	if (len(config.Normalizers) == 0 && !config.Folding) || keywords == nil {
+		return keywords
+	}
+
+	normalized := make(map[string]string, len(keywords))
+	for k, v := range keywords {
+		normalized[k] = v1Normalize(config, k, v)
+	}
+
+	return normalized
+}
+
+// v1ChangedKeywords returns the original values of the keywords normalization changed, nil if none
+func v1ChangedKeywords(original, normalized map[string]string) map[string]string {
+	var changed map[string]string
+	for k, v := range original {
+		if normalized[k] == v {
+			continue
+		}
+
+		if changed == nil {
+			changed = make(map[string]string)
+		}
+		changed[k] = v
+	}
+
+	return changed
+}
+
+// v1NormalizeQuery returns a copy of the query whose filter values are normalized like the stored keywords
+// and whose collation defaults to the index's
+func v1NormalizeQuery(config V1IndexConfig, query *V1RequestQuery) *V1RequestQuery {
+	if query.Collation == "" && config.Collation != "" {
+		collated := *query
+		collated.Collation = config.Collation
+		query = &collated
+	}
+
+	if (len(config.Normalizers) == 0 && !config.Folding) || len(query.Filters) == 0 {
+		return query
+	}
+
+	normalized := *query
+	normalized.Filters = make(map[string]string, len(query.Filters))
+	for k, filter := range query.Filters {
+		values := strings.Split(filter, ",")
+		for i, v := range values {
+			// Ranges compare numerically and aren't normalized
+			if _, ok := v1ParseFilterRange(v); !ok {
+				values[i] = v1Normalize(config, k, v)
+			}
+		}
+		normalized.Filters[k] = strings.Join(values, ",")
+	}
+
+	return &normalized
+}
@@ -0,0 +1,64 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1Normalizers(t *testing.T) {
+	index := v1TestIndex(t, "normalizers")
+
+	assert.NotNil(t, V1SetIndexConfig(nil, index, V1IndexConfig{
+		Normalizers: map[string][]string{"status": {"uppercase"}},
+	}))
+
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{
+		Normalizers: map[string][]string{
+			"status": {V1NormalizerTrim, V1NormalizerLowercase, V1NormalizerCollapseWhitespace},
+		},
+	}))
+
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"status": " Open"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2", Keywords: map[string]string{"status": "IN   PROGRESS "}}))
+
+	response, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{Filters: map[string]string{"status": "open"}}})
+	if assert.Equal(t, 1, response.Hits.Total) {
+		assert.Equal(t, "1", response.Hits.Hits[0].ID)
+		// The source keeps the value as it was put
+		assert.Equal(t, " Open", response.Hits.Hits[0].Source["status"])
+	}
+
+	// Query filters are normalized the same way
+	response, _ = V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{Filters: map[string]string{"status": "OPEN,In Progress"}}})
+	assert.Equal(t, 2, response.Hits.Total)
+}
+
+func TestV1Folding(t *testing.T) {
+	index := v1TestIndex(t, "folding")
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{Folding: true}))
+
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"name": "Café"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2", Keywords: map[string]string{"name": "Crème Brûlée"}}))
+
+	for _, filter := range []string{"cafe", "CAFÉ", "café"} {
+		response, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{Filters: map[string]string{"name": filter}}})
+		if assert.Equal(t, 1, response.Hits.Total, filter) {
+			assert.Equal(t, "1", response.Hits.Hits[0].ID)
+			assert.Equal(t, "Café", response.Hits.Hits[0].Source["name"])
+		}
+	}
+
+	response, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{Filters: map[string]string{"name": "creme brulee"}}})
+	assert.Equal(t, 1, response.Hits.Total)
+
+	// The fold normalizer does the same per field
+	field := v1TestIndex(t, "folding-field")
+	assert.Nil(t, V1SetIndexConfig(nil, field, V1IndexConfig{Normalizers: map[string][]string{"name": {V1NormalizerFold}}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: field, ID: "1", Keywords: map[string]string{"name": "Café", "city": "Besançon"}}))
+
+	response, _ = V1(nil, &V1Request{Index: field, Query: &V1RequestQuery{Filters: map[string]string{"name": "cafe"}}})
+	assert.Equal(t, 1, response.Hits.Total)
+	response, _ = V1(nil, &V1Request{Index: field, Query: &V1RequestQuery{Filters: map[string]string{"city": "besancon"}}})
+	assert.Equal(t, 0, response.Hits.Total)
+}
@@ -0,0 +1,110 @@
+package search
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	V1OverflowPolicyReject   = "reject"
+	V1OverflowPolicyEvictLRU = "evict_lru"
+)
+
+// v1OverflowPolicy decides what V1Index does when every slot is taken, guarded by v1IndexLock
+var v1OverflowPolicy = V1OverflowPolicyReject
+
+// V1SetOverflowPolicy sets what happens when a new index doesn't fit, "reject" (the default)
+// fails the creation and "evict_lru" drops the least recently queried or written index
+func V1SetOverflowPolicy(policy string) error {
+	switch policy {
+	case V1OverflowPolicyReject, V1OverflowPolicyEvictLRU:
+	default:
+		return fmt.Errorf("unknown overflow policy %s", policy)
+	}
+
+	v1IndexLock.Lock()
+	defer v1IndexLock.Unlock()
+
+	v1OverflowPolicy = policy
+
+	return nil
+}
+
+// touch records an access for the LRU eviction
+func (w *v1IndexWrapper) touch() {
+	atomic.StoreInt64(&w.lastAccess, time.Now().UnixNano())
+}
+
+// v1EvictColdest drops the least recently used index and returns its offset and name,
+// the caller must hold v1IndexLock
+func v1EvictColdest() (int, string, *v1Evictions) {
+	coldest := -1
+	for i := 0; i < v1IndexCapacity; i++ {
+		if !v1Indices[i].Initialized {
+			continue
+		}
+
+		if coldest < 0 || atomic.LoadInt64(&v1Indices[i].lastAccess) < atomic.LoadInt64(&v1Indices[coldest].lastAccess) {
+			coldest = i
+		}
+	}
+
+	if coldest < 0 {
+		return -1, "", nil
+	}
+
+	name := v1Indices[coldest].Name
+	evictions := v1Drop(coldest)
+
+	return coldest, name, evictions
+}
+
+// v1Drop frees the slot of the index and returns its docs for OnEvict, the caller must hold v1IndexLock
+func v1Drop(offset int) *v1Evictions {
+	w := v1Indices[offset]
+
+	w.Lock.Lock()
+	defer w.Lock.Unlock()
+
+	return w.drop()
+}
+
+// drop frees the slot of the index and returns its docs for OnEvict, the caller must hold v1IndexLock
+// and the write lock of the index
+func (w *v1IndexWrapper) drop() *v1Evictions {
+	evictions := w.evictions(w.all()...)
+
+	delete(v1IndexMapping, w.Name)
+
+	w.Initialized = false
+	w.Name = ""
+	w.Config = V1IndexConfig{}
+	w.synonyms = nil
+	w.readOnly = false
+	w.reset()
+	w.seqNo = 0
+	w.docs = atomic.Value{}
+
+	return evictions
+}
+
+// v1DropIndex frees the slot of the index, it reports false if the index doesn't exist
+func v1DropIndex(index string) bool {
+	v1IndexLock.Lock()
+	offset, found := v1IndexMapping[index]
+	var evictions *v1Evictions
+	if found {
+		evictions = v1Drop(offset)
+	}
+	v1IndexLock.Unlock()
+
+	evictions.notify()
+
+	if found {
+		V1DisableAutoSnapshot(nil, index)
+	}
+	v1ForgetSpill(index)
+
+	return found
+}
@@ -0,0 +1,102 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// v1TestIndex drops the index once the test is done, so tests don't run out of slots
+func v1TestIndex(t *testing.T, index string) string {
+	t.Cleanup(func() {
+		v1DropIndex(index)
+	})
+
+	return index
+}
+
+func TestV1OverflowEvictLRU(t *testing.T) {
+	cold := v1TestIndex(t, "overflow-cold")
+	evicted := make([]string, 0)
+	assert.Nil(t, V1SetIndexConfig(nil, cold, V1IndexConfig{OnEvict: func(doc *V1Doc) {
+		evicted = append(evicted, doc.ID)
+	}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: cold, ID: "1"}))
+
+	// Fill up every remaining slot
+	for i := 0; ; i++ {
+		if err := V1Index(nil, v1TestIndex(t, fmt.Sprintf("overflow-%d", i))); err != nil {
+			break
+		}
+	}
+
+	assert.NotNil(t, V1Index(nil, "overflow-new"))
+	assert.NotNil(t, V1SetOverflowPolicy("overflow"))
+	assert.Nil(t, V1SetOverflowPolicy(V1OverflowPolicyEvictLRU))
+	defer V1SetOverflowPolicy(V1OverflowPolicyReject)
+
+	// Everything is warmer than the cold index
+	for i := 0; i < v1IndexCapacity; i++ {
+		if v1Indices[i].Initialized {
+			v1Indices[i].touch()
+		}
+	}
+	v1Indices[V1GetIndexMapping(cold)].lastAccess = 0
+
+	events := make([]*V1ChangeEvent, 0)
+	cancel := V1Watch(func(event *V1ChangeEvent) {
+		events = append(events, event)
+	})
+	defer cancel()
+
+	assert.Nil(t, V1Index(nil, v1TestIndex(t, "overflow-new")))
+
+	assert.Equal(t, -1, V1GetIndexMapping(cold))
+	assert.GreaterOrEqual(t, V1GetIndexMapping("overflow-new"), 0)
+	if peek, err := V1Peak(nil, "overflow-new"); assert.Nil(t, err) {
+		assert.Equal(t, 0, peek.Total)
+	}
+
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, V1EventIndexEvicted, events[0].Type)
+		assert.Equal(t, cold, events[0].Index)
+	}
+
+	// Its docs leave along with it
+	assert.Equal(t, []string{"1"}, evicted)
+}
+
+func TestV1LockIndexSlotReused(t *testing.T) {
+	evicted, reused := v1TestIndex(t, "slot-evicted"), v1TestIndex(t, "slot-reused")
+	assert.Nil(t, V1Put(nil, &V1Request{Index: evicted, ID: "1"}))
+
+	// A lookup of the index waits on its slot, which is handed to another index meanwhile
+	w := v1Indices[V1GetIndexMapping(evicted)]
+	w.Lock.Lock()
+	peeked := make(chan error)
+	go func() {
+		_, err := V1Peak(nil, evicted)
+		peeked <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	v1IndexLock.Lock()
+	offset := v1IndexMapping[evicted]
+	delete(v1IndexMapping, evicted)
+	w.Name = reused
+	v1IndexMapping[reused] = offset
+	v1IndexLock.Unlock()
+	w.Lock.Unlock()
+
+	assert.True(t, errors.Is(<-peeked, ErrIndexNotFound))
+
+	// The writes create the index again rather than writing to the other one
+	assert.Nil(t, V1Put(nil, &V1Request{Index: evicted, ID: "2"}))
+	assert.NotEqual(t, offset, V1GetIndexMapping(evicted))
+	if peek, err := V1Peak(nil, reused); assert.Nil(t, err) {
+		assert.Equal(t, 1, peek.Total)
+	}
+}
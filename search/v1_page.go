@@ -0,0 +1,122 @@
+package search
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// v1PageToken is the search-after position behind NextPageToken, the sort values of the last hit
+// along with the hash of the query they belong to
+type v1PageToken struct {
+	Hash       string            `json:"h"`
+	Script     float64           `json:"c,omitempty"`
+	Score      float64           `json:"s,omitempty"`
+	Keywords   map[string]string `json:"k,omitempty"`
+	SortableID int64             `json:"o,omitempty"`
+	ID         string            `json:"i"`
+	Index      string            `json:"x,omitempty"`
+	SeqNo      int64             `json:"q,omitempty"`
+	Distance   float64           `json:"d,omitempty"`
+}
+
+// v1QueryHash identifies what a request matches and how it's sorted, paging doesn't change it
+func v1QueryHash(request *V1Request) string {
+	hashed := struct {
+		Query          *V1EchoedQuery     `json:"query"`
+		PostFilter     *V1EchoedQuery     `json:"post_filter,omitempty"`
+		ExternalScores map[string]float64 `json:"external_scores,omitempty"`
+		ScoreCombiner  string             `json:"score_combiner,omitempty"`
+		ExternalWeight float64            `json:"external_weight,omitempty"`
+		Indices        []string           `json:"indices,omitempty"`
+		Decay          *V1Decay           `json:"decay,omitempty"`
+	}{
+		Query:          v1EchoQuery(request.Index, request.Query),
+		Indices:        request.Indices,
+		Decay:          request.Decay,
+		ExternalScores: request.ExternalScores,
+		ScoreCombiner:  request.ScoreCombiner,
+		ExternalWeight: request.ExternalWeight,
+	}
+
+	if request.PostFilter != nil {
+		hashed.PostFilter = v1EchoQuery(request.Index, request.PostFilter)
+	}
+
+	encoded, _ := json.Marshal(hashed)
+	sum := md5.Sum(encoded)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// v1EncodePageToken encodes the position right after the recall
+func v1EncodePageToken(hash string, query *V1RequestQuery, recall *v1Recall) string {
+	token := &v1PageToken{
+		Hash:       hash,
+		Script:     recall.Script,
+		Score:      recall.Score,
+		SortableID: recall.Doc.SortableID,
+		ID:         recall.Doc.ID,
+		Index:      recall.Doc.Index,
+		SeqNo:      recall.Doc.SeqNo,
+		Distance:   recall.Distance,
+	}
+
+	// +Inf doesn't survive JSON, a missing location is encoded as a negative distance
+	if math.IsInf(token.Distance, 1) {
+		token.Distance = -1
+	}
+
+	for _, sortBy := range strings.Split(query.SortBys, ",") {
+		if v, found := recall.Doc.Keywords[sortBy]; found {
+			if token.Keywords == nil {
+				token.Keywords = make(map[string]string)
+			}
+			token.Keywords[sortBy] = v
+		}
+	}
+
+	encoded, _ := json.Marshal(token)
+
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+// v1DecodePageToken decodes the token into a recall carrying the sort values of the last hit,
+// the hits of the next page are the ones ordered after it
+func v1DecodePageToken(hash, encoded string) (*v1Recall, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid page token")
+	}
+
+	token := &v1PageToken{}
+	if err := json.Unmarshal(decoded, token); err != nil {
+		return nil, fmt.Errorf("invalid page token")
+	}
+
+	if token.Hash != hash {
+		return nil, fmt.Errorf("page token doesn't belong to the query")
+	}
+
+	distance := token.Distance
+	if distance < 0 {
+		distance = math.Inf(1)
+	}
+
+	return &v1Recall{
+		Doc: &V1Doc{
+			ID:         token.ID,
+			Index:      token.Index,
+			SortableID: token.SortableID,
+			Keywords:   token.Keywords,
+			SeqNo:      token.SeqNo,
+		},
+		Score:    token.Score,
+		Script:   token.Script,
+		Distance: distance,
+	}, nil
+}
@@ -0,0 +1,53 @@
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1PageToken(t *testing.T) {
+	index := v1TestIndex(t, "page-token")
+	for i := 0; i < 10; i++ {
+		// Few distinct values, so the pages break inside runs of ties
+		assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: fmt.Sprintf("doc-%d", i), Keywords: map[string]string{"group": fmt.Sprint(i % 3)}}))
+	}
+
+	query := &V1RequestQuery{SortBys: "group", SortMode: "asc"}
+	all, _ := V1(nil, &V1Request{Index: index, Query: query, Size: 10})
+	assert.Equal(t, 10, all.Hits.Total)
+	assert.Empty(t, all.NextPageToken)
+
+	var paged []string
+	token := ""
+	for i := 0; i < 3; i++ {
+		response, _ := V1(nil, &V1Request{Index: index, Query: query, Size: 3, PageToken: token})
+		assert.Equal(t, 10, response.Hits.Total)
+		assert.Len(t, response.Hits.Hits, 3)
+		assert.NotEmpty(t, response.NextPageToken)
+		for _, hit := range response.Hits.Hits {
+			paged = append(paged, hit.ID)
+		}
+		token = response.NextPageToken
+	}
+
+	// The last page has no token
+	response, _ := V1(nil, &V1Request{Index: index, Query: query, Size: 3, PageToken: token})
+	assert.Len(t, response.Hits.Hits, 1)
+	assert.Empty(t, response.NextPageToken)
+	paged = append(paged, response.Hits.Hits[0].ID)
+
+	// No overlaps or gaps
+	for i, hit := range all.Hits.Hits {
+		assert.Equal(t, hit.ID, paged[i])
+	}
+
+	// The token only fits the query it came from
+	other := &V1RequestQuery{SortBys: "group", SortMode: "asc", RegsAnd: map[string]*regexp.Regexp{"group": regexp.MustCompile("1")}}
+	_, err := V1(nil, &V1Request{Index: index, Query: other, Size: 3, PageToken: token})
+	assert.NotNil(t, err)
+	_, err = V1(nil, &V1Request{Index: index, Query: query, Size: 3, PageToken: "garbage"})
+	assert.NotNil(t, err)
+}
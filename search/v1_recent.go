@@ -0,0 +1,95 @@
+package search
+
+import (
+	"container/list"
+	"fmt"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// v1RecentIndex keeps the docs ordered by ModifiedAt desc, docs modified in the same second
+// are ordered by write, latest first
+type v1RecentIndex struct {
+	order    *list.List
+	elements map[string]*list.Element
+}
+
+func newV1RecentIndex(docs map[string]*V1Doc) *v1RecentIndex {
+	sorted := make([]*V1Doc, 0, len(docs))
+	for _, doc := range docs {
+		sorted = append(sorted, doc)
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ModifiedAt > sorted[j].ModifiedAt
+	})
+
+	r := &v1RecentIndex{
+		order:    list.New(),
+		elements: make(map[string]*list.Element, len(sorted)),
+	}
+
+	for _, doc := range sorted {
+		r.elements[doc.ID] = r.order.PushBack(doc)
+	}
+
+	return r
+}
+
+// set places the doc before every doc not modified after it, which is the front for a fresh write
+func (r *v1RecentIndex) set(doc *V1Doc) {
+	r.remove(doc.ID)
+
+	for e := r.order.Front(); e != nil; e = e.Next() {
+		if e.Value.(*V1Doc).ModifiedAt <= doc.ModifiedAt {
+			r.elements[doc.ID] = r.order.InsertBefore(doc, e)
+			return
+		}
+	}
+
+	r.elements[doc.ID] = r.order.PushBack(doc)
+}
+
+func (r *v1RecentIndex) remove(id string) {
+	if e, found := r.elements[id]; found {
+		r.order.Remove(e)
+		delete(r.elements, id)
+	}
+}
+
+// oldest returns the least recently modified doc whose ID isn't skipped, nil if there's none
+func (r *v1RecentIndex) oldest(skip map[string]*V1Doc) *V1Doc {
+	for e := r.order.Back(); e != nil; e = e.Prev() {
+		doc := e.Value.(*V1Doc)
+		if _, found := skip[doc.ID]; !found {
+			return doc
+		}
+	}
+
+	return nil
+}
+
+func (r *v1RecentIndex) top(n int) []*V1Doc {
+	docs := make([]*V1Doc, 0, n)
+	for e := r.order.Front(); e != nil && len(docs) < n; e = e.Next() {
+		docs = append(docs, v1CopyDoc(e.Value.(*V1Doc)))
+	}
+
+	return docs
+}
+
+// V1Recent returns copies of the n most recently modified docs, latest first
+func V1Recent(ctx *gin.Context, index string, n int) ([]*V1Doc, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("invalid recent count %d", n)
+	}
+
+	w, unlock, err := v1LockIndex(index, false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return w.recent.top(n), nil
+}
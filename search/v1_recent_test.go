@@ -0,0 +1,48 @@
+package search
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1Recent(t *testing.T) {
+	index := v1TestIndex(t, "recent")
+
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: fmt.Sprint(i + 1)}))
+	}
+
+	// Rewriting a doc makes it the latest
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2", Keywords: map[string]string{"v": "2"}}))
+
+	recent, err := V1Recent(nil, index, 3)
+	if assert.Nil(t, err) {
+		ids := make([]string, 0)
+		for _, doc := range recent {
+			ids = append(ids, doc.ID)
+		}
+		assert.Equal(t, []string{"2", "5", "4"}, ids)
+	}
+
+	recent, _ = V1Recent(nil, index, 10)
+	assert.Len(t, recent, 5)
+	for i := 1; i < len(recent); i++ {
+		assert.GreaterOrEqual(t, recent[i-1].ModifiedAt, recent[i].ModifiedAt)
+	}
+
+	// Older docs restored from elsewhere are placed by their ModifiedAt
+	offset := V1GetIndexMapping(index)
+	v1Indices[offset].Lock.Lock()
+	v1Indices[offset].set(&V1Doc{ID: "old", ModifiedAt: 1})
+	v1Indices[offset].Lock.Unlock()
+
+	recent, _ = V1Recent(nil, index, 10)
+	if assert.Len(t, recent, 6) {
+		assert.Equal(t, "old", recent[5].ID)
+	}
+
+	_, err = V1Recent(nil, "recent-missing", 3)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,70 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// V1Reindex puts a copy of every source doc into dest, see V1ReindexByQuery
+func V1Reindex(ctx *gin.Context, source, dest string) (int, error) {
+	return V1ReindexByQuery(ctx, source, dest, nil)
+}
+
+// V1ReindexByQuery puts a copy of the source docs matching the query into dest and returns how many were copied,
+// a nil query copies every doc. Dest is created if needed and keeps its own config, so the copies are normalized
+// and analyzed like any put into it
+func V1ReindexByQuery(ctx *gin.Context, source, dest string, query *V1RequestQuery) (int, error) {
+	if source == dest {
+		return 0, fmt.Errorf("can't reindex %s into itself", source)
+	}
+
+	if query == nil {
+		query = &V1RequestQuery{}
+	}
+
+	w, unlock, err := v1LockIndex(source, false)
+	if err != nil {
+		return 0, err
+	}
+
+	query = v1NormalizeQuery(w.Config, v1ExpandSynonyms(w.synonyms, query))
+	docs := make([]*V1Doc, 0)
+	for _, doc := range w.Naive {
+		if v1Match(query, doc, nil).Matched {
+			docs = append(docs, v1CopyDoc(doc))
+		}
+	}
+	unlock()
+
+	// Replay them in the order they were written
+	sort.Slice(docs, func(i, j int) bool {
+		return docs[i].SeqNo < docs[j].SeqNo
+	})
+
+	requests := make([]*V1Request, 0, len(docs))
+	for _, doc := range docs {
+		// Put the original values, dest normalizes them its own way
+		for k, v := range doc.RawKeywords {
+			doc.Keywords[k] = v
+		}
+
+		requests = append(requests, &V1Request{
+			Index:      dest,
+			ID:         doc.ID,
+			Keywords:   doc.Keywords,
+			Source:     doc.Source,
+			BoostValue: doc.BoostValue,
+		})
+	}
+
+	for i, request := range requests {
+		// A copy dest already has unchanged still counts as copied
+		if _, err := v1Put(ctx, request); err != nil {
+			return i, err
+		}
+	}
+
+	return len(requests), nil
+}
@@ -0,0 +1,48 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1ReindexByQuery(t *testing.T) {
+	source := v1TestIndex(t, "reindex-source")
+	dest := v1TestIndex(t, "reindex-dest")
+
+	assert.Nil(t, V1SetIndexConfig(nil, source, V1IndexConfig{Normalizers: map[string][]string{"name": {V1NormalizerLowercase}}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: source, ID: "1", Keywords: map[string]string{"status": "active", "name": "Ann"}, Source: map[string]interface{}{"age": 30.0}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: source, ID: "2", Keywords: map[string]string{"status": "inactive", "name": "Bob"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: source, ID: "3", Keywords: map[string]string{"status": "active", "name": "Cid"}}))
+
+	copied, err := V1ReindexByQuery(nil, source, dest, &V1RequestQuery{Filters: map[string]string{"status": "active"}})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, copied)
+
+	response, _ := V1(nil, &V1Request{Index: dest, Query: &V1RequestQuery{SortMode: "asc"}})
+	if assert.Equal(t, 2, response.Hits.Total) {
+		assert.Equal(t, "1", response.Hits.Hits[0].ID)
+		assert.Equal(t, dest, response.Hits.Hits[0].Index)
+		assert.Equal(t, 30.0, response.Hits.Hits[0].Source["age"])
+		assert.Equal(t, "3", response.Hits.Hits[1].ID)
+	}
+
+	// Dest keeps its own config, the original values come along
+	assert.Equal(t, "Ann", response.Hits.Hits[0].Source["name"])
+
+	// The source is untouched
+	response, _ = V1(nil, &V1Request{Index: source, Query: &V1RequestQuery{}})
+	assert.Equal(t, 3, response.Hits.Total)
+
+	// Everything without a query
+	copied, err = V1Reindex(nil, source, dest)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, copied)
+	response, _ = V1(nil, &V1Request{Index: dest, Query: &V1RequestQuery{}})
+	assert.Equal(t, 3, response.Hits.Total)
+
+	_, err = V1ReindexByQuery(nil, source, source, nil)
+	assert.NotNil(t, err)
+	_, err = V1ReindexByQuery(nil, "reindex-missing", dest, nil)
+	assert.NotNil(t, err)
+}
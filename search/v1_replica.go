@@ -0,0 +1,86 @@
+package search
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// V1AttachReplica loads the snapshot into a query-only index, writes to it fail with ErrReadOnly
+// until V1RefreshReplica swaps in a newer snapshot. Attaching to an existing replica refreshes it
+func V1AttachReplica(ctx *gin.Context, index string, snapshot io.Reader) error {
+	if w, unlock, err := v1LockIndex(index, false); err == nil {
+		replica := w.readOnly
+		unlock()
+
+		if !replica {
+			return fmt.Errorf("index %s exists and isn't a replica", index)
+		}
+	}
+
+	decoded, err := v1DecodeSnapshot(index, snapshot)
+	if err != nil {
+		return err
+	}
+
+	w, unlock, err := v1CreateAndLockIndex(ctx, index)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if !w.readOnly && len(w.Naive) > 0 {
+		// Written to in between
+		return fmt.Errorf("index %s exists and isn't a replica", index)
+	}
+
+	w.readOnly = true
+	w.restore(decoded)
+
+	return nil
+}
+
+// V1RefreshReplica replaces the docs and config of the replica with the snapshot at once,
+// queries see either the old docs or the new ones
+func V1RefreshReplica(ctx *gin.Context, index string, snapshot io.Reader) error {
+	// Decoded ahead, so queries aren't blocked while the snapshot is read
+	decoded, err := v1DecodeSnapshot(index, snapshot)
+	if err != nil {
+		return err
+	}
+
+	w, unlock, err := v1LockIndex(index, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if !w.readOnly {
+		return fmt.Errorf("index %s isn't a replica", index)
+	}
+
+	w.restore(decoded)
+
+	return nil
+}
+
+// V1StartReplicaRefresher refreshes the replica with the snapshot opened by open periodically until stop or
+// V1Shutdown is called. A failed refresh keeps the current docs, it's retried on the next tick
+func V1StartReplicaRefresher(index string, interval time.Duration, open func() (io.ReadCloser, error)) (stop func(), err error) {
+	w, err := v1StartWorker(interval, func() {
+		r, err := open()
+		if err != nil {
+			return
+		}
+		defer r.Close()
+
+		V1RefreshReplica(nil, index, r)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return w.stop, nil
+}
@@ -0,0 +1,73 @@
+package search
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1Replica(t *testing.T) {
+	primary := v1TestIndex(t, "replica-primary")
+	replica := v1TestIndex(t, "replica")
+
+	assert.Nil(t, V1Put(nil, &V1Request{Index: primary, ID: "1", Keywords: map[string]string{"name": "a"}}))
+	snapshot := &bytes.Buffer{}
+	assert.Nil(t, V1Snapshot(nil, primary, snapshot))
+	assert.Nil(t, V1AttachReplica(nil, replica, snapshot))
+
+	names := func() []string {
+		response, err := V1(nil, &V1Request{Index: replica, Query: &V1RequestQuery{SortBys: "name", SortMode: "asc"}})
+		assert.Nil(t, err)
+		names := make([]string, 0)
+		for _, hit := range response.Hits.Hits {
+			names = append(names, hit.Source["name"].(string))
+		}
+		return names
+	}
+	assert.Equal(t, []string{"a"}, names())
+
+	// Writes are rejected
+	assert.True(t, errors.Is(V1Put(nil, &V1Request{Index: replica, ID: "2"}), ErrReadOnly))
+	assert.True(t, errors.Is(V1Delete(nil, replica, "1"), ErrReadOnly))
+	assert.True(t, errors.Is(V1Reset(nil, replica), ErrReadOnly))
+	assert.True(t, errors.Is(V1SetIndexConfig(nil, replica, V1IndexConfig{}), ErrReadOnly))
+	_, err := V1Reshard(nil, replica, 2)
+	assert.True(t, errors.Is(err, ErrReadOnly))
+	assert.True(t, errors.Is(V1Restore(nil, replica, bytes.NewBufferString("{}")), ErrReadOnly))
+	assert.Equal(t, []string{"a"}, names())
+
+	// A refresh swaps in the newer snapshot
+	assert.Nil(t, V1Put(nil, &V1Request{Index: primary, ID: "2", Keywords: map[string]string{"name": "b"}}))
+	snapshot.Reset()
+	assert.Nil(t, V1Snapshot(nil, primary, snapshot))
+	assert.Nil(t, V1RefreshReplica(nil, replica, snapshot))
+	assert.Equal(t, []string{"a", "b"}, names())
+	assert.True(t, errors.Is(V1Put(nil, &V1Request{Index: replica, ID: "3"}), ErrReadOnly))
+
+	// A bad snapshot keeps the current docs
+	assert.NotNil(t, V1RefreshReplica(nil, replica, bytes.NewBufferString("{")))
+	assert.Equal(t, []string{"a", "b"}, names())
+
+	// The refresher picks up the snapshots written by the primary
+	path := V1SnapshotPath(t.TempDir(), primary)
+	assert.Nil(t, V1Put(nil, &V1Request{Index: primary, ID: "3", Keywords: map[string]string{"name": "c"}}))
+	assert.Nil(t, V1SnapshotToFile(nil, primary, path))
+	stop, err := V1StartReplicaRefresher(replica, 5*time.Millisecond, func() (io.ReadCloser, error) {
+		return os.Open(path)
+	})
+	assert.Nil(t, err)
+	defer stop()
+	assert.Eventually(t, func() bool {
+		return len(names()) == 3
+	}, time.Second, 5*time.Millisecond)
+
+	// Only replicas are attached to and refreshed
+	assert.NotNil(t, V1AttachReplica(nil, primary, bytes.NewBufferString("{}")))
+	assert.NotNil(t, V1RefreshReplica(nil, primary, bytes.NewBufferString("{}")))
+	assert.True(t, errors.Is(V1RefreshReplica(nil, "replica-missing", bytes.NewBufferString("{}")), ErrIndexNotFound))
+}
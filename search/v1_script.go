@@ -0,0 +1,212 @@
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"unicode"
+)
+
+// v1Script is a compiled arithmetic expression over numeric keyword fields,
+// only +, -, *, /, parentheses, numeric literals and field names are supported
+type v1Script struct {
+	source string
+	root   v1ScriptNode
+}
+
+type v1ScriptNode interface {
+	eval(doc *V1Doc) (float64, error)
+}
+
+type v1ScriptNumber float64
+
+func (n v1ScriptNumber) eval(doc *V1Doc) (float64, error) {
+	return float64(n), nil
+}
+
+type v1ScriptField string
+
+func (f v1ScriptField) eval(doc *V1Doc) (float64, error) {
+	return v1NumericValue(doc, string(f))
+}
+
+type v1ScriptNegate struct {
+	operand v1ScriptNode
+}
+
+func (n *v1ScriptNegate) eval(doc *V1Doc) (float64, error) {
+	v, err := n.operand.eval(doc)
+	return -v, err
+}
+
+type v1ScriptBinary struct {
+	op          byte
+	left, right v1ScriptNode
+}
+
+func (b *v1ScriptBinary) eval(doc *V1Doc) (float64, error) {
+	l, err := b.left.eval(doc)
+	if err != nil {
+		return 0, err
+	}
+
+	r, err := b.right.eval(doc)
+	if err != nil {
+		return 0, err
+	}
+
+	switch b.op {
+	case '+':
+		return l + r, nil
+	case '-':
+		return l - r, nil
+	case '*':
+		return l * r, nil
+	default:
+		if r == 0 {
+			return 0, fmt.Errorf("doc %s divides by zero", doc.ID)
+		}
+		return l / r, nil
+	}
+}
+
+// v1ParseScript compiles the expression, rejecting anything but arithmetic over fields and numbers
+func v1ParseScript(source string) (*v1Script, error) {
+	p := &v1ScriptParser{source: source}
+
+	root, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+	if p.pos < len(p.source) {
+		return nil, fmt.Errorf("script %q: unexpected %q at %d", source, p.source[p.pos], p.pos)
+	}
+
+	return &v1Script{source: source, root: root}, nil
+}
+
+func (s *v1Script) eval(doc *V1Doc) (float64, error) {
+	return s.root.eval(doc)
+}
+
+type v1ScriptParser struct {
+	source string
+	pos    int
+}
+
+func (p *v1ScriptParser) skipSpaces() {
+	for p.pos < len(p.source) && p.source[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *v1ScriptParser) peek() byte {
+	p.skipSpaces()
+	if p.pos < len(p.source) {
+		return p.source[p.pos]
+	}
+
+	return 0
+}
+
+// parseExpr parses term (('+' | '-') term)*
+func (p *v1ScriptParser) parseExpr() (v1ScriptNode, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	for op := p.peek(); op == '+' || op == '-'; op = p.peek() {
+		p.pos++
+
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &v1ScriptBinary{op: op, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseTerm parses factor (('*' | '/') factor)*
+func (p *v1ScriptParser) parseTerm() (v1ScriptNode, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+
+	for op := p.peek(); op == '*' || op == '/'; op = p.peek() {
+		p.pos++
+
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &v1ScriptBinary{op: op, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseFactor parses a number, a field, a negation or a parenthesized expression
+func (p *v1ScriptParser) parseFactor() (v1ScriptNode, error) {
+	c := p.peek()
+
+	switch {
+	case c == 0:
+		return nil, fmt.Errorf("script %q: unexpected end", p.source)
+	case c == '-':
+		p.pos++
+
+		operand, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+
+		return &v1ScriptNegate{operand: operand}, nil
+	case c == '(':
+		p.pos++
+
+		node, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.peek() != ')' {
+			return nil, fmt.Errorf("script %q: missing ) at %d", p.source, p.pos)
+		}
+		p.pos++
+
+		return node, nil
+	case c == '.' || ('0' <= c && c <= '9'):
+		start := p.pos
+		for p.pos < len(p.source) && (p.source[p.pos] == '.' || ('0' <= p.source[p.pos] && p.source[p.pos] <= '9')) {
+			p.pos++
+		}
+
+		n, err := strconv.ParseFloat(p.source[start:p.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf("script %q: invalid number %q", p.source, p.source[start:p.pos])
+		}
+
+		return v1ScriptNumber(n), nil
+	case c == '_' || unicode.IsLetter(rune(c)):
+		start := p.pos
+		for p.pos < len(p.source) && (p.source[p.pos] == '_' || unicode.IsLetter(rune(p.source[p.pos])) || unicode.IsDigit(rune(p.source[p.pos]))) {
+			p.pos++
+		}
+
+		name := p.source[start:p.pos]
+		if p.peek() == '(' {
+			return nil, fmt.Errorf("script %q: functions are not supported: %s", p.source, name)
+		}
+
+		return v1ScriptField(name), nil
+	default:
+		return nil, fmt.Errorf("script %q: unsupported %q at %d", p.source, c, p.pos)
+	}
+}
@@ -0,0 +1,77 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1ParseScript(t *testing.T) {
+	doc := &V1Doc{ID: "1", Keywords: map[string]string{"likes": "10", "dislikes": "4", "name": "x"}}
+
+	for source, expected := range map[string]float64{
+		"likes - dislikes":         6,
+		"likes - dislikes * 2":     2,
+		"(likes - dislikes) * 2":   12,
+		"-likes / 4 + 0.5":         -2,
+		"likes*likes/(dislikes+1)": 20,
+	} {
+		script, err := v1ParseScript(source)
+		if assert.Nil(t, err, source) {
+			v, err := script.eval(doc)
+			assert.Nil(t, err, source)
+			assert.Equal(t, expected, v, source)
+		}
+	}
+
+	for _, source := range []string{"likes % 2", "exec(likes)", "likes -", "(likes", "likes; dislikes", "likes == 1"} {
+		_, err := v1ParseScript(source)
+		assert.NotNil(t, err, source)
+	}
+
+	for _, source := range []string{"name + 1", "missing * 2", "likes / (dislikes - 4)"} {
+		script, err := v1ParseScript(source)
+		if assert.Nil(t, err, source) {
+			_, err = script.eval(doc)
+			assert.NotNil(t, err, source)
+		}
+	}
+}
+
+func TestV1ScriptSort(t *testing.T) {
+	index := v1TestIndex(t, "script-sort")
+
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"likes": "10", "dislikes": "8"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2", Keywords: map[string]string{"likes": "5", "dislikes": "0"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "3", Keywords: map[string]string{"likes": "100", "dislikes": "90"}}))
+
+	response, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{ScriptSort: "likes - dislikes", SortMode: "desc"}})
+
+	ids := make([]string, 0)
+	for _, hit := range response.Hits.Hits {
+		ids = append(ids, hit.ID)
+	}
+	assert.Equal(t, []string{"3", "2", "1"}, ids)
+
+	_, err := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{ScriptSort: "os.Exit(1)"}})
+	assert.NotNil(t, err)
+}
+
+func TestV1ScriptSortWarnings(t *testing.T) {
+	index := v1TestIndex(t, "script-sort-warnings")
+
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"price": "10"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2", Keywords: map[string]string{"price": "ten"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "3", Keywords: map[string]string{"price": "30"}}))
+
+	response, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{ScriptSort: "price * 2"}})
+
+	if assert.Equal(t, 2, response.Hits.Total) {
+		assert.Equal(t, "3", response.Hits.Hits[0].ID)
+		assert.Equal(t, "1", response.Hits.Hits[1].ID)
+	}
+
+	if assert.Len(t, response.Warnings, 1) {
+		assert.Contains(t, response.Warnings[0], "doc 2 field price is not numeric")
+	}
+}
@@ -0,0 +1,149 @@
+package search
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// v1RingReplicas is the number of virtual nodes per shard on the hash ring
+const v1RingReplicas = 128
+
+var (
+	v1RingLock = &sync.Mutex{}
+	// v1Rings caches the ring of each shard count, a ring only depends on its count
+	v1Rings = make(map[int]*v1HashRing)
+)
+
+// v1HashRing assigns ids to shards by consistent hashing, so changing the shard count
+// only moves the ids whose ring segment changed owner
+type v1HashRing struct {
+	points []uint32
+	owners map[uint32]int
+}
+
+func newV1HashRing(shards int) *v1HashRing {
+	ring := &v1HashRing{
+		points: make([]uint32, 0, shards*v1RingReplicas),
+		owners: make(map[uint32]int, shards*v1RingReplicas),
+	}
+
+	for shard := 0; shard < shards; shard++ {
+		for replica := 0; replica < v1RingReplicas; replica++ {
+			point := v1Hash(fmt.Sprintf("shard-%d-%d", shard, replica))
+			if _, taken := ring.owners[point]; taken {
+				continue
+			}
+
+			ring.owners[point] = shard
+			ring.points = append(ring.points, point)
+		}
+	}
+
+	sort.Slice(ring.points, func(i, j int) bool {
+		return ring.points[i] < ring.points[j]
+	})
+
+	return ring
+}
+
+// shard returns the owner of the first point clockwise from the id's hash
+func (r *v1HashRing) shard(id string) int {
+	h := v1Hash(id)
+
+	i := sort.Search(len(r.points), func(i int) bool {
+		return r.points[i] >= h
+	})
+	if i == len(r.points) {
+		i = 0
+	}
+
+	return r.owners[r.points[i]]
+}
+
+// v1Hash spreads short and sequential keys evenly over the ring
+func v1Hash(key string) uint32 {
+	sum := md5.Sum([]byte(key))
+	return binary.BigEndian.Uint32(sum[:4])
+}
+
+// shardOf returns the shard the ring assigns the id to, 0 without a ring
+func (r *v1HashRing) shardOf(id string) int {
+	if r == nil {
+		return 0
+	}
+
+	return r.shard(id)
+}
+
+// v1RingOf returns the ring of the shard count, nil for 0 or 1 shard
+func v1RingOf(shards int) *v1HashRing {
+	if shards <= 1 {
+		return nil
+	}
+
+	v1RingLock.Lock()
+	defer v1RingLock.Unlock()
+
+	ring, found := v1Rings[shards]
+	if !found {
+		ring = newV1HashRing(shards)
+		v1Rings[shards] = ring
+	}
+
+	return ring
+}
+
+// reshard changes the shard count of the index and returns the number of docs whose shard changed,
+// the caller must hold the write lock
+func (w *v1IndexWrapper) reshard(shards int) int {
+	oldRing, newRing := v1RingOf(w.Config.Shards), v1RingOf(shards)
+	w.Config.Shards = shards
+	if oldRing == newRing {
+		return 0
+	}
+
+	moved := 0
+	for id := range w.Naive {
+		if oldRing.shardOf(id) != newRing.shardOf(id) {
+			moved++
+		}
+	}
+
+	return moved
+}
+
+// V1ShardOf returns the shard the doc is assigned to, always 0 for an unsharded index. The shards are an
+// assignment only, the docs of all of them are stored, written and queried together
+func V1ShardOf(ctx *gin.Context, index, id string) (int, error) {
+	w, unlock, err := v1LockIndex(index, false)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	return v1RingOf(w.Config.Shards).shardOf(id), nil
+}
+
+// V1Reshard changes the shard count of the index and returns the number of docs moved to another shard
+func V1Reshard(ctx *gin.Context, index string, shards int) (int, error) {
+	if shards < 0 {
+		return 0, fmt.Errorf("invalid shard count %d", shards)
+	}
+
+	w, unlock, err := v1LockIndex(index, true)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	if err := w.writable(); err != nil {
+		return 0, err
+	}
+
+	return w.reshard(shards), nil
+}
@@ -0,0 +1,64 @@
+package search
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1Reshard(t *testing.T) {
+	index := v1TestIndex(t, "reshard")
+
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{Shards: 4}))
+
+	total := 1000
+	for i := 0; i < total; i++ {
+		assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: fmt.Sprint(i + 1)}))
+	}
+
+	before := make(map[string]int, total)
+	counts := make(map[int]int)
+	for i := 0; i < total; i++ {
+		id := fmt.Sprint(i + 1)
+		shard, err := V1ShardOf(nil, index, id)
+		assert.Nil(t, err)
+		before[id] = shard
+		counts[shard]++
+	}
+
+	// Every shard gets a fair share
+	assert.Len(t, counts, 4)
+	for _, count := range counts {
+		assert.Greater(t, count, total/8)
+	}
+
+	moved, err := V1Reshard(nil, index, 5)
+	assert.Nil(t, err)
+
+	stayed := 0
+	for id, shard := range before {
+		after, _ := V1ShardOf(nil, index, id)
+		if after == shard {
+			stayed++
+		}
+	}
+
+	// Ideally 1/5 of the docs move to the new shard, ID%N would move 4/5
+	assert.Equal(t, total-stayed, moved)
+	assert.Less(t, moved, total*2/5)
+
+	config, _ := V1GetIndexConfig(nil, index)
+	assert.Equal(t, 5, config.Shards)
+
+	// Going back to a single shard moves the docs off the others
+	moved, err = V1Reshard(nil, index, 1)
+	assert.Nil(t, err)
+	assert.Greater(t, moved, 0)
+	shard, _ := V1ShardOf(nil, index, "1")
+	assert.Equal(t, 0, shard)
+
+	// Sharding is transparent to queries
+	response, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{}})
+	assert.Equal(t, total, response.Hits.Total)
+}
@@ -0,0 +1,228 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// v1Snapshot is the serialized form of an index
+type v1Snapshot struct {
+	Index  string        `json:"index"`
+	Config V1IndexConfig `json:"config"`
+	Docs   []*V1Doc      `json:"docs"`
+}
+
+var (
+	v1AutoSnapshotLock = &sync.Mutex{}
+	v1AutoSnapshots    = make(map[string]*v1AutoSnapshot)
+)
+
+type v1AutoSnapshot struct {
+	dir      string
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// V1Snapshot writes the config and docs of the index to w
+func V1Snapshot(ctx *gin.Context, index string, w io.Writer) error {
+	wrapper, unlock, err := v1LockIndex(index, false)
+	if err != nil {
+		return err
+	}
+
+	snapshot := &v1Snapshot{
+		Index:  index,
+		Config: wrapper.Config,
+		Docs:   make([]*V1Doc, 0, len(wrapper.Naive)),
+	}
+	for _, doc := range wrapper.Naive {
+		snapshot.Docs = append(snapshot.Docs, doc)
+	}
+	unlock()
+
+	// Stored docs are never modified in place, so they can be encoded outside the lock
+	return json.NewEncoder(w).Encode(snapshot)
+}
+
+// V1Restore replaces the config and docs of the index with a snapshot read from r, creating the index if needed
+func V1Restore(ctx *gin.Context, index string, r io.Reader) error {
+	snapshot, err := v1DecodeSnapshot(index, r)
+	if err != nil {
+		return err
+	}
+
+	w, unlock, err := v1CreateAndLockIndex(ctx, index)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := w.writable(); err != nil {
+		return err
+	}
+
+	w.restore(snapshot)
+
+	return nil
+}
+
+// v1DecodeSnapshot reads a snapshot from r, its docs are moved to index
+func v1DecodeSnapshot(index string, r io.Reader) (*v1Snapshot, error) {
+	snapshot := &v1Snapshot{}
+	if err := json.NewDecoder(r).Decode(snapshot); err != nil {
+		return nil, fmt.Errorf("decode snapshot: %w", err)
+	}
+
+	if err := snapshot.Config.validate(); err != nil {
+		return nil, err
+	}
+
+	for _, doc := range snapshot.Docs {
+		doc.Index = index
+	}
+
+	return snapshot, nil
+}
+
+// restore replaces the config and docs with the snapshot, the caller must hold the write lock
+func (w *v1IndexWrapper) restore(snapshot *v1Snapshot) {
+	// The callback isn't part of the snapshot, keep the current one
+	snapshot.Config.OnEvict = w.Config.OnEvict
+	w.Config = snapshot.Config
+	w.load(snapshot.Docs)
+}
+
+// V1SnapshotPath is where the snapshot file of the index lives in dir, the name is escaped
+// so separators and ".." can't lead out of dir
+func V1SnapshotPath(dir, index string) string {
+	return filepath.Join(dir, url.PathEscape(index)+".snapshot.json")
+}
+
+// V1SnapshotToFile writes the snapshot to a temp file and renames it over path,
+// so a crash never leaves a partial snapshot behind
+func V1SnapshotToFile(ctx *gin.Context, index, path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := V1Snapshot(ctx, index, tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// V1RestoreFromFile restores the index from a snapshot file
+func V1RestoreFromFile(ctx *gin.Context, index, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return V1Restore(ctx, index, f)
+}
+
+// V1EnableAutoSnapshot periodically snapshots the index into dir, replacing any previous schedule of the index
+func V1EnableAutoSnapshot(ctx *gin.Context, index, dir string, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("invalid auto snapshot interval %s", interval)
+	}
+
+	if offset := V1GetIndexMapping(index); offset < 0 {
+		return fmt.Errorf("%w: %s", ErrIndexNotFound, index)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	V1DisableAutoSnapshot(ctx, index)
+
+	auto := &v1AutoSnapshot{
+		dir:      dir,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	v1AutoSnapshotLock.Lock()
+	v1AutoSnapshots[index] = auto
+	v1AutoSnapshotLock.Unlock()
+
+	go func() {
+		defer close(auto.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-auto.stop:
+				return
+			case <-ticker.C:
+				v1Background(func() {
+					V1SnapshotToFile(nil, index, V1SnapshotPath(dir, index))
+				})
+			}
+		}
+	}()
+
+	return nil
+}
+
+// V1DisableAutoSnapshot stops the auto snapshot of the index and waits for an in-flight snapshot to finish
+func V1DisableAutoSnapshot(ctx *gin.Context, index string) {
+	v1TakeAutoSnapshot(index).halt()
+}
+
+// v1TakeAutoSnapshot unregisters the auto snapshot of the index and returns it, nil if there's none
+func v1TakeAutoSnapshot(index string) *v1AutoSnapshot {
+	v1AutoSnapshotLock.Lock()
+	defer v1AutoSnapshotLock.Unlock()
+
+	auto := v1AutoSnapshots[index]
+	delete(v1AutoSnapshots, index)
+
+	return auto
+}
+
+// resume schedules the halted auto snapshot for the index again, nil resumes nothing
+func (a *v1AutoSnapshot) resume(ctx *gin.Context, index string) {
+	if a == nil {
+		return
+	}
+
+	V1EnableAutoSnapshot(ctx, index, a.dir, a.interval)
+}
+
+// halt stops the unregistered auto snapshot and waits for an in-flight snapshot to finish
+func (a *v1AutoSnapshot) halt() {
+	if a == nil {
+		return
+	}
+
+	close(a.stop)
+	<-a.done
+}
@@ -0,0 +1,70 @@
+package search
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1SnapshotRestore(t *testing.T) {
+	index := v1TestIndex(t, "snapshot-source")
+
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{MaxDocs: 10}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"name": "a"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2", Keywords: map[string]string{"name": "b"}}))
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, V1Snapshot(nil, index, buffer))
+	restored := v1TestIndex(t, "snapshot-restored")
+	assert.Nil(t, V1Restore(nil, restored, buffer))
+
+	config, err := V1GetIndexConfig(nil, restored)
+	assert.Nil(t, err)
+	assert.Equal(t, 10, config.MaxDocs)
+
+	response, _ := V1(nil, &V1Request{Index: restored, Query: &V1RequestQuery{Filters: map[string]string{"name": "b"}}})
+	if assert.Equal(t, 1, response.Hits.Total) {
+		assert.Equal(t, "2", response.Hits.Hits[0].ID)
+		assert.Equal(t, restored, response.Hits.Hits[0].Index)
+	}
+}
+
+func TestV1AutoSnapshot(t *testing.T) {
+	index := v1TestIndex(t, "auto-snapshot")
+	dir := t.TempDir()
+
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"name": "a"}}))
+	assert.Nil(t, V1EnableAutoSnapshot(nil, index, dir, 10*time.Millisecond))
+
+	path := V1SnapshotPath(dir, index)
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}, time.Second, 5*time.Millisecond)
+
+	V1DisableAutoSnapshot(nil, index)
+
+	// No temp file is left behind
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	assert.Empty(t, matches)
+
+	restored := v1TestIndex(t, "auto-snapshot-restored")
+	assert.Nil(t, V1RestoreFromFile(nil, restored, path))
+	if peek, err := V1Peak(nil, restored); assert.Nil(t, err) {
+		assert.Equal(t, 1, peek.Total)
+	}
+}
+
+func TestV1SnapshotPath(t *testing.T) {
+	dir := filepath.Join("var", "snapshots")
+	assert.Equal(t, filepath.Join(dir, "orders-2024.snapshot.json"), V1SnapshotPath(dir, "orders-2024"))
+
+	for _, index := range []string{"../../x", "..", "a/b", `a\b`, "/etc/passwd"} {
+		path := V1SnapshotPath(dir, index)
+		assert.Equal(t, dir, filepath.Dir(path), index)
+	}
+}
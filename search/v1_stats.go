@@ -0,0 +1,52 @@
+package search
+
+// V1FieldStats summarizes the numeric values of a field over the matched docs,
+// Min, Max and Avg are 0 if Count is
+type V1FieldStats struct {
+	Count int     `json:"count"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Sum   float64 `json:"sum"`
+	Avg   float64 `json:"avg"`
+	// Skipped counts the docs whose value isn't numeric, docs without the field aren't counted at all
+	Skipped int `json:"skipped"`
+}
+
+// v1Stats computes the stats of the fields in a single pass over the docs
+func v1Stats(recalls []*v1Recall, fields []string) map[string]*V1FieldStats {
+	stats := make(map[string]*V1FieldStats, len(fields))
+	for _, field := range fields {
+		stats[field] = &V1FieldStats{}
+	}
+
+	for _, recall := range recalls {
+		for field, s := range stats {
+			if _, found := recall.Doc.Keywords[field]; !found {
+				continue
+			}
+
+			n, err := v1NumericValue(recall.Doc, field)
+			if err != nil {
+				s.Skipped++
+				continue
+			}
+
+			if s.Count == 0 || n < s.Min {
+				s.Min = n
+			}
+			if s.Count == 0 || n > s.Max {
+				s.Max = n
+			}
+			s.Count++
+			s.Sum += n
+		}
+	}
+
+	for _, s := range stats {
+		if s.Count > 0 {
+			s.Avg = s.Sum / float64(s.Count)
+		}
+	}
+
+	return stats
+}
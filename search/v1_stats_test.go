@@ -0,0 +1,37 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1StatsAggs(t *testing.T) {
+	index := v1TestIndex(t, "stats-aggs")
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{NumericFields: []string{"price"}}))
+
+	for id, keywords := range map[string]map[string]string{
+		"1": {"kind": "book", "price": "10", "stock": "3"},
+		"2": {"kind": "book", "price": "25.5", "stock": "n/a"},
+		"3": {"kind": "book", "price": "4.5"},
+		"4": {"kind": "book", "price": "free"},
+		"5": {"kind": "toy", "price": "100"},
+	} {
+		assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: id, Keywords: keywords}))
+	}
+
+	response, _ := V1(nil, &V1Request{
+		Index:     index,
+		Query:     &V1RequestQuery{Filters: map[string]string{"kind": "book"}},
+		StatsAggs: []string{"price", "stock", "missing"},
+	})
+
+	assert.Equal(t, 4, response.Hits.Total)
+	assert.Equal(t, &V1FieldStats{Count: 3, Min: 4.5, Max: 25.5, Sum: 40, Avg: 40.0 / 3, Skipped: 1}, response.Stats["price"])
+	assert.Equal(t, &V1FieldStats{Count: 1, Min: 3, Max: 3, Sum: 3, Avg: 3, Skipped: 1}, response.Stats["stock"])
+	assert.Equal(t, &V1FieldStats{}, response.Stats["missing"])
+
+	// Not computed unless asked
+	response, _ = V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{}})
+	assert.Nil(t, response.Stats)
+}
@@ -0,0 +1,131 @@
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// V1PutSynonyms creates the index if needed and replaces its synonyms, every term of a group matches
+// the others, e.g. {"tv", "television"}. They're applied at query time, so the docs aren't reindexed
+func V1PutSynonyms(ctx *gin.Context, index string, groups [][]string) error {
+	synonyms := make(map[string][]string)
+	for _, group := range groups {
+		if len(group) < 2 {
+			return fmt.Errorf("synonym group %v needs at least two terms", group)
+		}
+
+		for _, term := range group {
+			key := v1SynonymKey(term)
+			if key == "" {
+				return fmt.Errorf("invalid synonym %q", term)
+			}
+
+			for _, synonym := range group {
+				if v1SynonymKey(synonym) != key {
+					synonyms[key] = append(synonyms[key], synonym)
+				}
+			}
+		}
+	}
+
+	if err := V1Index(ctx, index); err != nil {
+		return err
+	}
+
+	w, unlock, err := v1LockIndex(index, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := w.writable(); err != nil {
+		return err
+	}
+
+	// Queries may now match differently
+	w.seqNo++
+	w.synonyms = synonyms
+
+	return nil
+}
+
+// v1SynonymKey is the analyzed form synonyms are looked up by
+func v1SynonymKey(term string) string {
+	return strings.Join(v1Tokenize(term), " ")
+}
+
+// v1ExpandSynonyms returns a copy of the query matching the synonyms of its terms too,
+// filter values get their synonyms added and literal regexes become an alternation of them
+func v1ExpandSynonyms(synonyms map[string][]string, query *V1RequestQuery) *V1RequestQuery {
+	if len(synonyms) == 0 {
+		return query
+	}
+
+	expanded := *query
+	expanded.synonyms = synonyms
+	expanded.RegsAnd = v1ExpandRegs(synonyms, query.RegsAnd)
+	expanded.RegsOr = v1ExpandRegs(synonyms, query.RegsOr)
+	expanded.AnyField = v1ExpandReg(synonyms, query.AnyField)
+
+	if query.MultiMatch != nil {
+		expanded.MultiMatch = &V1MultiMatch{
+			Pattern: v1ExpandReg(synonyms, query.MultiMatch.Pattern),
+			Fields:  query.MultiMatch.Fields,
+		}
+	}
+
+	if query.Filters != nil {
+		expanded.Filters = make(map[string]string, len(query.Filters))
+		for k, filter := range query.Filters {
+			values := strings.Split(filter, ",")
+			for _, v := range strings.Split(filter, ",") {
+				values = append(values, synonyms[v1SynonymKey(v)]...)
+			}
+			expanded.Filters[k] = strings.Join(values, ",")
+		}
+	}
+
+	return &expanded
+}
+
+func v1ExpandRegs(synonyms map[string][]string, regs map[string]*regexp.Regexp) map[string]*regexp.Regexp {
+	if regs == nil {
+		return nil
+	}
+
+	expanded := make(map[string]*regexp.Regexp, len(regs))
+	for k, reg := range regs {
+		expanded[k] = v1ExpandReg(synonyms, reg)
+	}
+
+	return expanded
+}
+
+// v1ExpandReg turns a regex matching a literal term into one matching its synonyms too,
+// other regexes are left alone
+func v1ExpandReg(synonyms map[string][]string, reg *regexp.Regexp) *regexp.Regexp {
+	if reg == nil {
+		return nil
+	}
+
+	literal, complete := reg.LiteralPrefix()
+	if !complete {
+		return reg
+	}
+
+	alternatives := synonyms[v1SynonymKey(literal)]
+	if len(alternatives) == 0 {
+		return reg
+	}
+
+	quoted := make([]string, 0, len(alternatives)+1)
+	quoted = append(quoted, regexp.QuoteMeta(literal))
+	for _, alternative := range alternatives {
+		quoted = append(quoted, regexp.QuoteMeta(alternative))
+	}
+
+	return regexp.MustCompile("(?:" + strings.Join(quoted, "|") + ")")
+}
@@ -0,0 +1,49 @@
+package search
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1Synonyms(t *testing.T) {
+	index := v1TestIndex(t, "synonyms")
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"title": "television set", "category": "television"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2", Keywords: map[string]string{"title": "radio", "category": "radio"}}))
+
+	queries := map[string]*V1RequestQuery{
+		"terms":   {Terms: map[string][]string{"title": {"tv"}}},
+		"regex":   {RegsAnd: map[string]*regexp.Regexp{"title": regexp.MustCompile("tv")}},
+		"filters": {Filters: map[string]string{"category": "tv"}},
+	}
+
+	for name, query := range queries {
+		response, _ := V1(nil, &V1Request{Index: index, Query: query})
+		assert.Equal(t, 0, response.Hits.Total, name)
+	}
+
+	assert.NotNil(t, V1PutSynonyms(nil, index, [][]string{{"tv"}}))
+	assert.Nil(t, V1PutSynonyms(nil, index, [][]string{{"tv", "television"}}))
+
+	// Applied at query time, the docs put before match too
+	for name, query := range queries {
+		response, _ := V1(nil, &V1Request{Index: index, Query: query})
+		if assert.Equal(t, 1, response.Hits.Total, name) {
+			assert.Equal(t, "1", response.Hits.Hits[0].ID, name)
+		}
+	}
+
+	explanation, err := V1ExplainDoc(nil, index, "1", queries["terms"])
+	assert.Nil(t, err)
+	assert.True(t, explanation.Matched)
+
+	// Non-literal regexes are left alone
+	response, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{RegsAnd: map[string]*regexp.Regexp{"title": regexp.MustCompile("^tv")}}})
+	assert.Equal(t, 0, response.Hits.Total)
+
+	// Synonyms can change without reindexing
+	assert.Nil(t, V1PutSynonyms(nil, index, nil))
+	response, _ = V1(nil, &V1Request{Index: index, Query: queries["terms"]})
+	assert.Equal(t, 0, response.Hits.Total)
+}
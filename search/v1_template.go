@@ -0,0 +1,70 @@
+package search
+
+import (
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	v1TemplateLock = &sync.RWMutex{}
+	// v1Templates maps an index name pattern to the config new matching indices start with
+	v1Templates = map[string]V1IndexConfig{}
+)
+
+// V1PutTemplate registers the config that indices created later with a name matching the pattern start with,
+// the pattern uses path.Match syntax, e.g. "logs-*"
+func V1PutTemplate(ctx *gin.Context, pattern string, config V1IndexConfig) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return err
+	}
+
+	if err := config.validate(); err != nil {
+		return err
+	}
+
+	v1TemplateLock.Lock()
+	defer v1TemplateLock.Unlock()
+
+	v1Templates[pattern] = v1CopyConfig(config)
+
+	return nil
+}
+
+// V1DeleteTemplate removes the template of the pattern, existing indices keep their config
+func V1DeleteTemplate(ctx *gin.Context, pattern string) bool {
+	v1TemplateLock.Lock()
+	defer v1TemplateLock.Unlock()
+
+	_, found := v1Templates[pattern]
+	delete(v1Templates, pattern)
+
+	return found
+}
+
+// v1MatchTemplate returns the config of the most specific template matching the index,
+// the one with the most literal characters, ties go to the lexically smaller pattern
+func v1MatchTemplate(index string) (V1IndexConfig, bool) {
+	v1TemplateLock.RLock()
+	defer v1TemplateLock.RUnlock()
+
+	best, bestLiterals := "", -1
+	for pattern := range v1Templates {
+		if matched, _ := path.Match(pattern, index); !matched {
+			continue
+		}
+
+		literals := len(pattern) - strings.Count(pattern, "*") - strings.Count(pattern, "?")
+		if literals > bestLiterals || (literals == bestLiterals && pattern < best) {
+			best, bestLiterals = pattern, literals
+		}
+	}
+
+	if bestLiterals < 0 {
+		return V1IndexConfig{}, false
+	}
+
+	return v1CopyConfig(v1Templates[best]), true
+}
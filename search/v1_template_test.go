@@ -0,0 +1,57 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1TemplateCollation(t *testing.T) {
+	assert.Nil(t, V1PutTemplate(nil, "logs-*", V1IndexConfig{Collation: "ZH-HANS_CI"}))
+	assert.Nil(t, V1PutTemplate(nil, "logs-raw-*", V1IndexConfig{MaxDocs: 10}))
+	t.Cleanup(func() {
+		V1DeleteTemplate(nil, "logs-*")
+		V1DeleteTemplate(nil, "logs-raw-*")
+	})
+	assert.NotNil(t, V1PutTemplate(nil, "logs-[", V1IndexConfig{}))
+
+	index := v1TestIndex(t, "logs-app")
+	for i, name := range []string{"姚", "明", "啊"} {
+		assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: string(rune('1' + i)), Keywords: map[string]string{"name": name}}))
+	}
+
+	// Auto-created indices inherit the template
+	config, err := V1GetIndexConfig(nil, index)
+	assert.Nil(t, err)
+	assert.Equal(t, "ZH-HANS_CI", config.Collation)
+
+	names := func(response *V1Response) []string {
+		var names []string
+		for _, hit := range response.Hits.Hits {
+			names = append(names, hit.Source["name"].(string))
+		}
+		return names
+	}
+
+	response, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{SortBys: "name", SortMode: "asc"}})
+	assert.Equal(t, []string{"啊", "明", "姚"}, names(response))
+
+	// The query can override the collation
+	response, _ = V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{SortBys: "name", SortMode: "asc", Collation: "BINARY"}})
+	assert.Equal(t, []string{"啊", "姚", "明"}, names(response))
+
+	// The most specific pattern wins
+	raw := v1TestIndex(t, "logs-raw-app")
+	assert.Nil(t, V1Index(nil, raw))
+	config, err = V1GetIndexConfig(nil, raw)
+	assert.Nil(t, err)
+	assert.Equal(t, "", config.Collation)
+	assert.Equal(t, 10, config.MaxDocs)
+
+	// Indices not matching any pattern keep the default config
+	other := v1TestIndex(t, "metrics-app")
+	assert.Nil(t, V1Index(nil, other))
+	config, err = V1GetIndexConfig(nil, other)
+	assert.Nil(t, err)
+	assert.Equal(t, V1IndexConfig{}, config)
+}
@@ -1,24 +1,33 @@
 package search
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/tidwall/collate"
 )
 
 func TestV1(t *testing.T) {
-	index := "hello"
+	index := v1TestIndex(t, "hello")
 
 	V1Index(nil, index)
 	V1Index(nil, index)
 	V1Index(nil, index)
 	V1Index(nil, index)
 	V1Index(nil, index)
-	V1Index(nil, "s")
-	V1Index(nil, "sss")
+	V1Index(nil, v1TestIndex(t, "s"))
+	V1Index(nil, v1TestIndex(t, "sss"))
 
 	V1Put(nil, &V1Request{
 		Index: index,
@@ -31,13 +40,305 @@ func TestV1(t *testing.T) {
 		},
 	})
 
-	response := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{}})
+	response, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{}})
 
 	if assert.Equal(t, true, response.Hits.Total > 0) {
-		assert.Equal(t, "123", response.Hits.Hits[0])
+		assert.Equal(t, "123", response.Hits.Hits[0].ID)
 	}
 }
 
+func TestV1CopyOnWriteConcurrent(t *testing.T) {
+	index := v1TestIndex(t, "copy-on-write")
+
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{CopyOnWrite: true}))
+
+	wg := &sync.WaitGroup{}
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				V1Put(nil, &V1Request{
+					Index:    index,
+					ID:       fmt.Sprint(w*100 + i + 1),
+					Keywords: map[string]string{"writer": fmt.Sprint(w)},
+				})
+			}
+		}(w)
+	}
+
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{Filters: map[string]string{"writer": "1"}}})
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	response, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{}})
+	assert.Equal(t, 400, response.Hits.Total)
+
+	response, _ = V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{Filters: map[string]string{"writer": "1"}}})
+	assert.Equal(t, 100, response.Hits.Total)
+}
+
+func BenchmarkV1ConcurrentReadWrite(b *testing.B) {
+	for _, copyOnWrite := range []bool{false, true} {
+		index := fmt.Sprintf("bench-cow-%v", copyOnWrite)
+		V1SetIndexConfig(nil, index, V1IndexConfig{CopyOnWrite: copyOnWrite})
+
+		for i := 0; i < 5000; i++ {
+			V1Put(nil, &V1Request{
+				Index:    index,
+				ID:       fmt.Sprint(i + 1),
+				Keywords: map[string]string{"bucket": fmt.Sprint(i % 10)},
+			})
+		}
+
+		b.Run(fmt.Sprintf("copy_on_write=%v", copyOnWrite), func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					i++
+					if i%10 == 0 {
+						V1Put(nil, &V1Request{
+							Index:    index,
+							ID:       fmt.Sprint(i%5000 + 1),
+							Keywords: map[string]string{"bucket": fmt.Sprint(i % 10)},
+						})
+						continue
+					}
+
+					V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{Filters: map[string]string{"bucket": "3"}}})
+				}
+			})
+		})
+	}
+}
+
+func TestV1SinceSeqNo(t *testing.T) {
+	index := v1TestIndex(t, "since-seq-no")
+
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1"}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2"}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "3"}))
+
+	// Initial sync
+	response, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{}})
+	assert.Equal(t, 3, response.Hits.Total)
+	assert.Equal(t, int64(3), response.SeqNo)
+	checkpoint := response.SeqNo
+
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2", Keywords: map[string]string{"v": "2"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "4"}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"v": "1"}}))
+
+	// Re-sync fetches the deltas in write order
+	response, _ = V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{SinceSeqNo: checkpoint}})
+	ids := make([]string, 0)
+	for _, hit := range response.Hits.Hits {
+		ids = append(ids, hit.ID)
+	}
+	assert.Equal(t, []string{"2", "4", "1"}, ids)
+	assert.Equal(t, int64(6), response.SeqNo)
+
+	// Nothing changed since
+	response, _ = V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{SinceSeqNo: response.SeqNo}})
+	assert.Equal(t, 0, response.Hits.Total)
+}
+
+func TestV1IndexVersion(t *testing.T) {
+	index := v1TestIndex(t, "index-version")
+
+	assert.Nil(t, V1Index(nil, index))
+
+	version, err := V1IndexVersion(nil, index)
+	assert.Nil(t, err)
+
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1"}))
+	afterPut, _ := V1IndexVersion(nil, index)
+	assert.Greater(t, afterPut, version)
+
+	V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{}})
+	afterRead, _ := V1IndexVersion(nil, index)
+	assert.Equal(t, afterPut, afterRead)
+
+	assert.NotNil(t, V1Delete(nil, index, "2"))
+	afterMissingDelete, _ := V1IndexVersion(nil, index)
+	assert.Equal(t, afterPut, afterMissingDelete)
+
+	assert.Nil(t, V1Delete(nil, index, "1"))
+	afterDelete, _ := V1IndexVersion(nil, index)
+	assert.Greater(t, afterDelete, afterPut)
+	if peek, err := V1Peak(nil, index); assert.Nil(t, err) {
+		assert.Equal(t, 0, peek.Total)
+	}
+
+	_, err = V1IndexVersion(nil, "index-version-missing")
+	assert.NotNil(t, err)
+}
+
+func TestV1ResetDuringQueries(t *testing.T) {
+	index := v1TestIndex(t, "reset-during-queries")
+	V1Index(nil, index)
+
+	wg := &sync.WaitGroup{}
+	stop := make(chan struct{})
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			V1Put(nil, &V1Request{
+				Index:    index,
+				ID:       fmt.Sprint(i%50 + 1),
+				Keywords: map[string]string{"name": fmt.Sprint(i)},
+				Source:   map[string]interface{}{"nested": map[string]interface{}{"i": i}},
+			})
+			if i%20 == 0 {
+				V1Reset(nil, index)
+			}
+		}
+	}()
+
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				response, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{SortBys: "name"}, Size: 50})
+				for _, hit := range response.Hits.Hits {
+					// Callers own what they get back
+					hit.Source["name"] = "changed"
+					hit.Source["nested"].(map[string]interface{})["i"] = -1
+				}
+			}
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+
+	response, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{}, Size: 50})
+	for _, hit := range response.Hits.Hits {
+		assert.NotEqual(t, "changed", hit.Source["name"])
+	}
+}
+
+func TestV1MaxScan(t *testing.T) {
+	for _, copyOnWrite := range []bool{false, true} {
+		index := v1TestIndex(t, fmt.Sprintf("max-scan-%t", copyOnWrite))
+		assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{CopyOnWrite: copyOnWrite}))
+		for i := 1; i <= 10; i++ {
+			assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: strconv.Itoa(i)}))
+		}
+
+		// Every doc matches, so the scan stopping early shows in the total
+		response, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{}, MaxScan: 3})
+		assert.Equal(t, 3, response.Hits.Total)
+		assert.True(t, response.Approximate)
+
+		// A limit covering the index is exact
+		response, _ = V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{}, MaxScan: 10})
+		assert.Equal(t, 10, response.Hits.Total)
+		assert.False(t, response.Approximate)
+
+		response, _ = V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{}})
+		assert.Equal(t, 10, response.Hits.Total)
+		assert.False(t, response.Approximate)
+	}
+}
+
+func TestV1Timeout(t *testing.T) {
+	index := v1TestIndex(t, "timeout")
+	for i := 0; i < 100000; i++ {
+		assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: strconv.Itoa(i + 1), Keywords: map[string]string{"name": fmt.Sprintf("doc %d", i)}}))
+	}
+
+	query := &V1RequestQuery{RegsAnd: map[string]*regexp.Regexp{"name": regexp.MustCompile(`^doc \d*[02468]$`)}, SortMode: "asc"}
+
+	response, _ := V1(nil, &V1Request{Index: index, Query: query})
+	assert.Equal(t, 50000, response.Hits.Total)
+	assert.False(t, response.TimedOut)
+
+	response, _ = V1(nil, &V1Request{Index: index, Query: query, TimeoutMs: 1, Size: 5})
+	assert.True(t, response.TimedOut)
+	assert.Less(t, response.Hits.Total, 50000)
+
+	// What was collected is still sorted
+	for i := 1; i < len(response.Hits.Hits); i++ {
+		previous, _ := strconv.Atoi(response.Hits.Hits[i-1].ID)
+		current, _ := strconv.Atoi(response.Hits.Hits[i].ID)
+		assert.Less(t, previous, current)
+	}
+
+	// A canceled request stops the scan too
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	ctx := &gin.Context{Request: httptest.NewRequest(http.MethodPost, "/", nil).WithContext(canceled)}
+	response, _ = V1(ctx, &V1Request{Index: index, Query: query})
+	assert.True(t, response.TimedOut)
+	assert.Equal(t, 0, response.Hits.Total)
+}
+
+func TestV1DocsScanned(t *testing.T) {
+	index := v1TestIndex(t, "docs-scanned")
+	for i := 0; i < 50; i++ {
+		assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: strconv.Itoa(i + 1), Keywords: map[string]string{"name": "doc"}}))
+	}
+
+	response, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{}})
+	assert.Equal(t, 50, response.DocsScanned)
+	assert.Equal(t, 50*len("namedoc"), response.BytesScanned)
+
+	// Only the scanned docs count, not the matched ones
+	response, _ = V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{Filters: map[string]string{"name": "none"}}})
+	assert.Equal(t, 0, response.Hits.Total)
+	assert.Equal(t, 50, response.DocsScanned)
+
+	response, _ = V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{IDs: []string{"1", "2"}}})
+	assert.Equal(t, 2, response.DocsScanned)
+	assert.Equal(t, 2*len("namedoc"), response.BytesScanned)
+}
+
+func TestV1OpTypeCreate(t *testing.T) {
+	index := v1TestIndex(t, "op-type")
+
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", OpType: V1OpTypeCreate, Keywords: map[string]string{"v": "1"}}))
+	assert.NotNil(t, V1Put(nil, &V1Request{Index: index, ID: "1", OpType: V1OpTypeCreate, Keywords: map[string]string{"v": "2"}}))
+	assert.NotNil(t, V1Put(nil, &V1Request{Index: index, ID: "2", OpType: "upsert"}))
+
+	// The failed create left the doc alone
+	response, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{}})
+	if assert.Equal(t, 1, response.Hits.Total) {
+		assert.Equal(t, "1", response.Hits.Hits[0].Source["v"])
+	}
+
+	// Index overwrites
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", OpType: V1OpTypeIndex, Keywords: map[string]string{"v": "3"}}))
+	response, _ = V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{}})
+	assert.Equal(t, "3", response.Hits.Hits[0].Source["v"])
+
+	bulk := V1Bulk(nil, []*V1Request{
+		{Index: index, ID: "1", OpType: V1OpTypeCreate},
+		{Index: index, ID: "2", OpType: V1OpTypeCreate},
+	})
+	assert.Equal(t, 1, bulk.Created)
+	assert.Equal(t, 1, bulk.Errors)
+	assert.Equal(t, V1ResultError, bulk.Items[0].Result)
+}
+
 func TestV2(t *testing.T) {
 	request := &V1Request{
 		Query: &V1RequestQuery{
@@ -0,0 +1,37 @@
+package search
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// V1Top returns a copy of the first doc of the index sorted by the sortBy keyword in mode, "asc" or else desc,
+// the same doc a V1 query with these SortBys and SortMode hits first, found by a single pass instead of a sort
+func V1Top(ctx *gin.Context, index string, sortBy string, mode string) (*V1Doc, error) {
+	index = v1ResolveAlias(index)
+	w, unlock, err := v1LockIndex(index, false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	w.touch()
+
+	query := v1NormalizeQuery(w.Config, &V1RequestQuery{SortBys: sortBy, SortMode: mode})
+	less := v1RecallLess(query)
+
+	var top *v1Recall
+	for _, doc := range w.Naive {
+		recall := &v1Recall{Doc: doc}
+		if top == nil || less(recall, top) {
+			top = recall
+		}
+	}
+
+	if top == nil {
+		return nil, fmt.Errorf("%w: index %s is empty", ErrDocNotFound, index)
+	}
+
+	return v1CopyDoc(top.Doc), nil
+}
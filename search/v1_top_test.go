@@ -0,0 +1,54 @@
+package search
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1Top(t *testing.T) {
+	index := v1TestIndex(t, "top")
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{Collation: "en"}))
+
+	_, err := V1Top(nil, index, "name", "asc")
+	assert.True(t, errors.Is(err, ErrDocNotFound))
+
+	for i, name := range []string{"banana", "Apple", "cherry", "apple", "Éclair", "date", "cherry"} {
+		assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: strconv.Itoa(i + 1), Keywords: map[string]string{"name": name, "rank": strconv.Itoa(i % 3)}}))
+	}
+
+	for _, sortBy := range []string{"name", "rank", "missing"} {
+		for _, mode := range []string{"asc", "desc"} {
+			top, err := V1Top(nil, index, sortBy, mode)
+			assert.Nil(t, err)
+
+			response, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{SortBys: sortBy, SortMode: mode}, Size: 1})
+			assert.Equal(t, response.Hits.Hits[0].ID, top.ID, "%s %s", sortBy, mode)
+		}
+	}
+
+	// The returned doc is a copy
+	top, _ := V1Top(nil, index, "name", "desc")
+	top.Keywords["name"] = "changed"
+	again, _ := V1Top(nil, index, "name", "desc")
+	assert.NotEqual(t, "changed", again.Keywords["name"])
+
+	_, err = V1Top(nil, "top-missing", "name", "asc")
+	assert.True(t, errors.Is(err, ErrIndexNotFound))
+}
+
+func TestV1TopNumeric(t *testing.T) {
+	index := v1TestIndex(t, "top-numeric")
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{NumericFields: []string{"price"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"price": "9"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2", Keywords: map[string]string{"price": "10"}}))
+
+	if top, err := V1Top(nil, index, "price", "desc"); assert.Nil(t, err) {
+		assert.Equal(t, "10", top.Keywords["price"])
+	}
+	if top, err := V1Top(nil, index, "price", "asc"); assert.Nil(t, err) {
+		assert.Equal(t, "9", top.Keywords["price"])
+	}
+}
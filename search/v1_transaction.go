@@ -0,0 +1,233 @@
+package search
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	V1OpPut    = "put"
+	V1OpUpdate = "update"
+	V1OpDelete = "delete"
+)
+
+// V1Op is a write of a transaction, the Request holds the doc of a put or the ID and the changed keywords
+// and source of an update, only its ID is read for a delete
+type V1Op struct {
+	Op      string     `json:"op"`
+	Request *V1Request `json:"request"`
+}
+
+// v1StagedOp is a validated op of a transaction waiting for the commit, doc is nil for a delete or a noop.
+// evict is the oldest doc a put into a full index with the evict oldest policy makes room by
+type v1StagedOp struct {
+	op    string
+	id    string
+	doc   *V1Doc
+	evict string
+}
+
+// V1Transaction applies the ops to the index in order under a single write lock, all or nothing: if any of
+// them fails, none is applied and the error of the first is returned. Change events are emitted on commit only
+func V1Transaction(ctx *gin.Context, index string, ops []V1Op) error {
+	for i, op := range ops {
+		if op.Request == nil {
+			return fmt.Errorf("op %d: missing request", i)
+		}
+
+		switch op.Op {
+		case V1OpPut:
+			if err := v1ValidatePut(op.Request); err != nil {
+				return fmt.Errorf("op %d: %w", i, err)
+			}
+		case V1OpUpdate, V1OpDelete:
+			if op.Request.ID == "" {
+				return fmt.Errorf("op %d: missing doc id", i)
+			}
+		default:
+			return fmt.Errorf("op %d: unknown op %s", i, op.Op)
+		}
+	}
+
+	// Deferred ahead of the unlock, so the events are emitted after the lock is released
+	var events []*V1ChangeEvent
+	var evictions *v1Evictions
+	defer func() {
+		evictions.notify()
+		for _, event := range events {
+			v1Emit(event)
+		}
+	}()
+
+	w, unlock, err := v1CreateAndLockIndex(ctx, index)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := w.writable(); err != nil {
+		return err
+	}
+
+	staged, err := w.stage(index, ops)
+	if err != nil {
+		return err
+	}
+
+	w.touch()
+
+	evicted := make([]*V1Doc, 0)
+	for _, s := range staged {
+		switch {
+		case s.op == V1OpDelete:
+			doc := w.Naive[s.id]
+			if doc == nil {
+				continue
+			}
+			w.seqNo++
+			w.remove(s.id)
+			if w.Config.SoftDeleteWindow > 0 {
+				// It only leaves the index once it expires
+				if replaced := w.trashDoc(doc); replaced != nil {
+					evicted = append(evicted, replaced)
+				}
+			} else {
+				evicted = append(evicted, doc)
+			}
+			events = append(events, &V1ChangeEvent{Type: V1EventDelete, Index: index, ID: s.id})
+		case s.doc != nil:
+			if oldest := w.Naive[s.evict]; oldest != nil {
+				w.remove(oldest.ID)
+				evicted = append(evicted, oldest)
+			}
+			w.seqNo++
+			s.doc.SeqNo = w.seqNo
+			w.set(s.doc)
+			events = append(events, &V1ChangeEvent{Type: V1EventPut, Index: index, ID: s.id})
+		}
+	}
+	evictions = w.evictions(evicted...)
+
+	return nil
+}
+
+// stage validates the ops against the index as it would be after each of the ones before, without changing it.
+// The caller must hold the write lock
+func (w *v1IndexWrapper) stage(index string, ops []V1Op) ([]*v1StagedOp, error) {
+	// The docs as written by the staged ops, nil once deleted
+	docs := make(map[string]*V1Doc)
+	current := func(id string) *V1Doc {
+		if doc, found := docs[id]; found {
+			return doc
+		}
+		return w.Naive[id]
+	}
+
+	size := len(w.Naive)
+	staged := make([]*v1StagedOp, 0, len(ops))
+	for i, op := range ops {
+		request := op.Request
+		request.Index = index
+
+		switch op.Op {
+		case V1OpDelete:
+			if current(request.ID) == nil {
+				return nil, fmt.Errorf("op %d: %w: %s in index %s", i, ErrDocNotFound, request.ID, index)
+			}
+			docs[request.ID] = nil
+			size--
+			staged = append(staged, &v1StagedOp{op: op.Op, id: request.ID})
+			continue
+		case V1OpUpdate:
+			existing := current(request.ID)
+			if existing == nil {
+				return nil, fmt.Errorf("op %d: %w: %s in index %s", i, ErrDocNotFound, request.ID, index)
+			}
+			request = v1UpdateRequest(existing, request)
+			if err := v1ValidatePut(request); err != nil {
+				return nil, fmt.Errorf("op %d: %w", i, err)
+			}
+		}
+
+		doc, result, err := w.prepare(request, current(request.ID))
+		if err != nil {
+			return nil, fmt.Errorf("op %d: %w", i, err)
+		}
+
+		evict := ""
+		if result == V1ResultCreated {
+			if w.Config.MaxDocs > 0 && size >= w.Config.MaxDocs {
+				if w.Config.MaxDocsPolicy != V1MaxDocsPolicyEvictOldest {
+					return nil, fmt.Errorf("op %d: %w: index %s is full with %d docs", i, ErrCapacityExceeded, index, w.Config.MaxDocs)
+				}
+
+				// The later ops see the oldest doc gone, as it is by then
+				oldest := w.stagedOldest(docs, staged)
+				evict = oldest.ID
+				docs[evict] = nil
+				size--
+			}
+			size++
+		}
+
+		if doc != nil {
+			docs[request.ID] = doc
+		}
+		staged = append(staged, &v1StagedOp{op: op.Op, id: request.ID, doc: doc, evict: evict})
+	}
+
+	return staged, nil
+}
+
+// stagedOldest returns the least recently modified doc of the index as the staged docs leave it. The docs
+// the batch doesn't touch are older than any it writes, which are then taken in the order of the ops.
+// The caller must hold the lock
+func (w *v1IndexWrapper) stagedOldest(docs map[string]*V1Doc, staged []*v1StagedOp) *V1Doc {
+	if oldest := w.recent.oldest(docs); oldest != nil {
+		return oldest
+	}
+
+	for _, s := range staged {
+		// Skips the writes a later op of the batch replaced or deleted
+		if s.doc != nil && docs[s.id] == s.doc {
+			return s.doc
+		}
+	}
+
+	return nil
+}
+
+// v1UpdateRequest merges the keywords and source of the update into a copy of the existing doc,
+// the boost is kept unless the update sets one
+func v1UpdateRequest(existing *V1Doc, update *V1Request) *V1Request {
+	keywords := make(map[string]string, len(existing.Keywords)+len(update.Keywords))
+	for k, v := range existing.Keywords {
+		keywords[k] = v
+	}
+	// Normalized again from the original values
+	for k, v := range existing.RawKeywords {
+		keywords[k] = v
+	}
+	for k, v := range update.Keywords {
+		keywords[k] = v
+	}
+
+	source := v1CopyValue(existing.Source).(map[string]interface{})
+	for k, v := range update.Source {
+		source[k] = v
+	}
+
+	boost := existing.BoostValue
+	if update.BoostValue != 0 {
+		boost = update.BoostValue
+	}
+
+	return &V1Request{
+		Index:      update.Index,
+		ID:         update.ID,
+		Keywords:   keywords,
+		Source:     source,
+		BoostValue: boost,
+	}
+}
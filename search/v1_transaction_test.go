@@ -0,0 +1,154 @@
+package search
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1Transaction(t *testing.T) {
+	index := v1TestIndex(t, "transaction")
+
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"name": "a", "color": "red"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2", Keywords: map[string]string{"name": "b"}}))
+
+	lock := &sync.Mutex{}
+	events := make([]*V1ChangeEvent, 0)
+	cancel := V1Watch(func(event *V1ChangeEvent) {
+		if event.Index == index {
+			lock.Lock()
+			events = append(events, event)
+			lock.Unlock()
+		}
+	})
+	defer cancel()
+
+	get := func(id string) map[string]interface{} {
+		response, err := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{IDs: []string{id}}})
+		assert.Nil(t, err)
+		if len(response.Hits.Hits) == 0 {
+			return nil
+		}
+		return response.Hits.Hits[0].Source
+	}
+
+	// One invalid op rolls back the whole batch
+	err := V1Transaction(nil, index, []V1Op{
+		{Op: V1OpPut, Request: &V1Request{ID: "3", Keywords: map[string]string{"name": "c"}}},
+		{Op: V1OpUpdate, Request: &V1Request{ID: "1", Keywords: map[string]string{"name": "z"}}},
+		{Op: V1OpDelete, Request: &V1Request{ID: "2"}},
+		{Op: V1OpDelete, Request: &V1Request{ID: "missing"}},
+	})
+	assert.True(t, errors.Is(err, ErrDocNotFound))
+	assert.Nil(t, get("3"))
+	assert.Equal(t, "a", get("1")["name"])
+	assert.NotNil(t, get("2"))
+	assert.Empty(t, events)
+
+	// Create conflicts with a doc put earlier in the same batch
+	err = V1Transaction(nil, index, []V1Op{
+		{Op: V1OpPut, Request: &V1Request{ID: "3"}},
+		{Op: V1OpPut, Request: &V1Request{ID: "3", OpType: V1OpTypeCreate}},
+	})
+	assert.True(t, errors.Is(err, ErrVersionConflict))
+	assert.Nil(t, get("3"))
+
+	// An update of a doc deleted earlier in the batch fails
+	err = V1Transaction(nil, index, []V1Op{
+		{Op: V1OpDelete, Request: &V1Request{ID: "2"}},
+		{Op: V1OpUpdate, Request: &V1Request{ID: "2"}},
+	})
+	assert.True(t, errors.Is(err, ErrDocNotFound))
+	assert.NotNil(t, get("2"))
+
+	assert.NotNil(t, V1Transaction(nil, index, []V1Op{{Op: "upsert", Request: &V1Request{ID: "1"}}}))
+	assert.NotNil(t, V1Transaction(nil, index, []V1Op{{Op: V1OpPut, Request: &V1Request{ID: "1", BoostValue: -1}}}))
+	assert.Empty(t, events)
+
+	// A valid batch is applied in order
+	assert.Nil(t, V1Transaction(nil, index, []V1Op{
+		{Op: V1OpPut, Request: &V1Request{ID: "3", Keywords: map[string]string{"name": "c"}}},
+		{Op: V1OpUpdate, Request: &V1Request{ID: "1", Keywords: map[string]string{"name": "z"}}},
+		{Op: V1OpDelete, Request: &V1Request{ID: "2"}},
+		{Op: V1OpUpdate, Request: &V1Request{ID: "3", Source: map[string]interface{}{"extra": true}}},
+	}))
+	assert.Equal(t, "c", get("3")["name"])
+	assert.Equal(t, true, get("3")["extra"])
+	assert.Equal(t, "z", get("1")["name"])
+	assert.Equal(t, "red", get("1")["color"])
+	assert.Nil(t, get("2"))
+	if peek, err := V1Peak(nil, index); assert.Nil(t, err) {
+		assert.Equal(t, 2, peek.Total)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	types := make([]string, 0)
+	for _, event := range events {
+		types = append(types, event.Type+":"+event.ID)
+	}
+	assert.Equal(t, []string{"put:3", "put:1", "delete:2", "put:3"}, types)
+}
+
+func TestV1TransactionMaxDocs(t *testing.T) {
+	index := v1TestIndex(t, "transaction-max-docs")
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{MaxDocs: 2}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1"}))
+
+	err := V1Transaction(nil, index, []V1Op{
+		{Op: V1OpPut, Request: &V1Request{ID: "2"}},
+		{Op: V1OpPut, Request: &V1Request{ID: "3"}},
+	})
+	assert.True(t, errors.Is(err, ErrCapacityExceeded))
+
+	// A delete in the batch makes room
+	assert.Nil(t, V1Transaction(nil, index, []V1Op{
+		{Op: V1OpPut, Request: &V1Request{ID: "2"}},
+		{Op: V1OpDelete, Request: &V1Request{ID: "1"}},
+		{Op: V1OpPut, Request: &V1Request{ID: "3"}},
+	}))
+	if peek, err := V1Peak(nil, index); assert.Nil(t, err) {
+		assert.Equal(t, 2, peek.Total)
+	}
+}
+
+func TestV1TransactionEvictOldest(t *testing.T) {
+	index := v1TestIndex(t, "transaction-evict-oldest")
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{MaxDocs: 2, MaxDocsPolicy: V1MaxDocsPolicyEvictOldest}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1"}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2"}))
+
+	ids := func() []string {
+		response, err := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{SortBys: "_id", SortMode: "asc"}})
+		assert.Nil(t, err)
+		ids := make([]string, 0)
+		for _, hit := range response.Hits.Hits {
+			ids = append(ids, hit.ID)
+		}
+		return ids
+	}
+
+	// The later ops see the doc the put evicts gone
+	err := V1Transaction(nil, index, []V1Op{
+		{Op: V1OpPut, Request: &V1Request{ID: "3"}},
+		{Op: V1OpDelete, Request: &V1Request{ID: "1"}},
+	})
+	assert.True(t, errors.Is(err, ErrDocNotFound))
+	err = V1Transaction(nil, index, []V1Op{
+		{Op: V1OpPut, Request: &V1Request{ID: "3"}},
+		{Op: V1OpUpdate, Request: &V1Request{ID: "1"}},
+	})
+	assert.True(t, errors.Is(err, ErrDocNotFound))
+	assert.ElementsMatch(t, []string{"1", "2"}, ids())
+
+	// Each put evicts the oldest doc left, the ones put earlier in the batch included
+	assert.Nil(t, V1Transaction(nil, index, []V1Op{
+		{Op: V1OpPut, Request: &V1Request{ID: "3"}},
+		{Op: V1OpDelete, Request: &V1Request{ID: "2"}},
+		{Op: V1OpPut, Request: &V1Request{ID: "4"}},
+		{Op: V1OpPut, Request: &V1Request{ID: "5"}},
+	}))
+	assert.ElementsMatch(t, []string{"4", "5"}, ids())
+}
@@ -0,0 +1,129 @@
+package search
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// v1TrashedDoc is a soft-deleted doc, it's hidden from queries but can be restored until it expires
+type v1TrashedDoc struct {
+	doc       *V1Doc
+	deletedAt time.Time
+}
+
+// trashDoc keeps the removed doc for the soft delete window and returns the earlier trashed doc of the id
+// it replaces, which leaves the index for good, the caller must hold the write lock
+func (w *v1IndexWrapper) trashDoc(doc *V1Doc) *V1Doc {
+	if w.trash == nil {
+		w.trash = make(map[string]*v1TrashedDoc)
+	}
+
+	var replaced *V1Doc
+	if trashed, found := w.trash[doc.ID]; found {
+		replaced = trashed.doc
+		w.bytes -= replaced.SourceBytes
+	}
+	w.trash[doc.ID] = &v1TrashedDoc{doc: doc, deletedAt: time.Now()}
+	w.bytes += doc.SourceBytes
+
+	return replaced
+}
+
+// untrash drops the doc from the trash, the caller must hold the write lock
+func (w *v1IndexWrapper) untrash(id string) {
+	if trashed, found := w.trash[id]; found {
+		w.bytes -= trashed.doc.SourceBytes
+		delete(w.trash, id)
+	}
+}
+
+// expired reports whether the soft delete window of the trashed doc has passed
+func (w *v1IndexWrapper) expired(trashed *v1TrashedDoc, now time.Time) bool {
+	return now.Sub(trashed.deletedAt) >= w.Config.SoftDeleteWindow
+}
+
+// V1Undelete restores a soft-deleted doc within the soft delete window
+func V1Undelete(ctx *gin.Context, index, id string) error {
+	w, unlock, err := v1LockIndex(index, true)
+	if err != nil {
+		return err
+	}
+
+	if err := w.writable(); err != nil {
+		unlock()
+		return err
+	}
+	trashed, found := w.trash[id]
+	if !found || w.expired(trashed, time.Now()) {
+		unlock()
+		return fmt.Errorf("%w: %s in the trash of index %s", ErrDocNotFound, id, index)
+	}
+
+	if _, found := w.Naive[id]; found {
+		unlock()
+		return fmt.Errorf("%w: doc %s was put again in index %s", ErrVersionConflict, id, index)
+	}
+
+	w.untrash(id)
+
+	// Stored docs are immutable, restore a copy stamped as a new write
+	w.touch()
+	w.seqNo++
+	restored := *trashed.doc
+	restored.SeqNo = w.seqNo
+	w.set(&restored)
+	unlock()
+
+	v1Emit(&V1ChangeEvent{Type: V1EventPut, Index: index, ID: id})
+
+	return nil
+}
+
+// V1Sweep purges the soft-deleted docs of the index whose window has passed and returns how many
+func V1Sweep(ctx *gin.Context, index string) (int, error) {
+	w, unlock, err := v1LockIndex(index, true)
+	if err != nil {
+		return 0, err
+	}
+
+	var purged []*V1Doc
+	now := time.Now()
+	for id, trashed := range w.trash {
+		if w.expired(trashed, now) {
+			w.untrash(id)
+			purged = append(purged, trashed.doc)
+		}
+	}
+	evictions := w.evictions(purged...)
+	unlock()
+
+	evictions.notify()
+
+	return len(purged), nil
+}
+
+// V1StartSweeper purges the expired soft-deleted docs of every index periodically until stop or V1Shutdown is called,
+// stop waits for an in-flight sweep to finish
+func V1StartSweeper(interval time.Duration) (stop func(), err error) {
+	w, err := v1StartWorker(interval, v1SweepAll)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.stop, nil
+}
+
+func v1SweepAll() {
+	v1IndexLock.RLock()
+	indices := make([]string, 0, len(v1IndexMapping))
+	for index := range v1IndexMapping {
+		indices = append(indices, index)
+	}
+	v1IndexLock.RUnlock()
+
+	for _, index := range indices {
+		V1Sweep(nil, index)
+	}
+}
@@ -0,0 +1,79 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1SoftDelete(t *testing.T) {
+	index := v1TestIndex(t, "trash")
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{SoftDeleteWindow: time.Hour}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1", Keywords: map[string]string{"name": "a"}}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2", Keywords: map[string]string{"name": "b"}}))
+
+	// Deleted docs are hidden from queries
+	assert.Nil(t, V1Delete(nil, index, "1"))
+	response, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{}})
+	assert.Equal(t, 1, response.Hits.Total)
+	assert.Equal(t, "2", response.Hits.Hits[0].ID)
+	assert.NotNil(t, V1Delete(nil, index, "1"))
+
+	// Undelete within the window restores the doc as a new write
+	assert.Nil(t, V1Undelete(nil, index, "1"))
+	response, _ = V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{SortBys: "name", SortMode: "asc"}})
+	assert.Equal(t, 2, response.Hits.Total)
+	assert.Equal(t, "1", response.Hits.Hits[0].ID)
+	assert.Equal(t, "a", response.Hits.Hits[0].Source["name"])
+	changes, _ := V1(nil, &V1Request{Index: index, Query: &V1RequestQuery{SinceSeqNo: 3}})
+	assert.Equal(t, 1, changes.Hits.Total)
+	assert.NotNil(t, V1Undelete(nil, index, "1"))
+
+	// A doc put again can't be undeleted over
+	assert.Nil(t, V1Delete(nil, index, "2"))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2", Keywords: map[string]string{"name": "c"}}))
+	assert.NotNil(t, V1Undelete(nil, index, "2"))
+
+	// Nothing is purged within the window
+	purged, err := V1Sweep(nil, index)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, purged)
+}
+
+func TestV1SoftDeletePurge(t *testing.T) {
+	index := v1TestIndex(t, "trash-purge")
+	assert.Nil(t, V1SetIndexConfig(nil, index, V1IndexConfig{SoftDeleteWindow: 10 * time.Millisecond}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "1"}))
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "2"}))
+	assert.Nil(t, V1Delete(nil, index, "1"))
+	assert.Nil(t, V1Delete(nil, index, "2"))
+
+	time.Sleep(20 * time.Millisecond)
+
+	// Expired docs can't be undeleted even before they're swept
+	assert.NotNil(t, V1Undelete(nil, index, "1"))
+
+	purged, err := V1Sweep(nil, index)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, purged)
+
+	// The sweeper purges in the background
+	assert.Nil(t, V1Put(nil, &V1Request{Index: index, ID: "3"}))
+	assert.Nil(t, V1Delete(nil, index, "3"))
+	stop, err := V1StartSweeper(5 * time.Millisecond)
+	assert.Nil(t, err)
+	defer stop()
+	assert.Eventually(t, func() bool {
+		offset := V1GetIndexMapping(index)
+		v1Indices[offset].Lock.RLock()
+		defer v1Indices[offset].Lock.RUnlock()
+		return len(v1Indices[offset].trash) == 0
+	}, time.Second, 5*time.Millisecond)
+
+	// Without a window deletes are for good
+	hard := v1TestIndex(t, "trash-hard")
+	assert.Nil(t, V1Put(nil, &V1Request{Index: hard, ID: "1"}))
+	assert.Nil(t, V1Delete(nil, hard, "1"))
+	assert.NotNil(t, V1Undelete(nil, hard, "1"))
+}